@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/sheets"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/tabular"
+	log "github.com/sirupsen/logrus"
+)
+
+// command is a subcommand of the binary
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, out io.Writer) error
+}
+
+func commands() []command {
+	return []command{
+		{
+			name:  "serve",
+			usage: "serve\n\tSync the sheets and serve the API, the default if no command is given",
+			run:   func(args []string, out io.Writer) error { return serve() },
+		},
+		{
+			name:  "sync",
+			usage: "sync [--source name]\n\tPull the sheets into postgres once and exit",
+			run:   runSync,
+		},
+		{
+			name:  "snapshot",
+			usage: "snapshot --out file.json|file.csv|file.xlsx\n\tCapture the live standings sheet to a file that SHEET_SNAPSHOT can serve from",
+			run:   runSnapshot,
+		},
+		{
+			name:  "migrate",
+			usage: "migrate\n\tMake any missing tables and exit",
+			run:   runMigrate,
+		},
+		{
+			name:  "export",
+			usage: "export teams|standings [--format json|csv] [--out file]\n\tDump teams or standings from postgres",
+			run:   runExport,
+		},
+		{
+			name:  "query",
+			usage: "query teams [--id id] [--name name] [--franchise name] [--conference name] [--tier name] [--division name] [--json]\n\tList teams matching the filters, each can be repeated",
+			run:   runQuery,
+		},
+	}
+}
+
+// printUsage lists every command
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(c.usage, "\n\t", "\n\t  "))
+	}
+}
+
+// runCommand runs the command named by the first arg, serve if there are no args
+func runCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	switch args[0] {
+	case "help", "-h", "--help":
+		printUsage(out)
+		return nil
+	}
+
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:], out)
+		}
+	}
+
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command: %s", args[0])
+}
+
+// stringsFlag is a flag that can be repeated to collect multiple values
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func runSync(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	source := flags.String("source", "", "only sync this source")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	mydb, err := db.OpenDB(dbConnStr(), makeSources())
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	defer mydb.Close()
+
+	runs, syncErr := mydb.Sync(context.Background(), *source)
+	if runs != nil {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(runs); err != nil {
+			return err
+		}
+	}
+	if syncErr != nil {
+		return fmt.Errorf("sync failed: %v", syncErr)
+	}
+	return nil
+}
+
+func runSnapshot(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	outPath := flags.String("out", "", "file to write the snapshot to, its extension picks the format")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *outPath == "" {
+		return errUsage
+	}
+	format, err := sheets.SnapshotFormat(*outPath)
+	if err != nil {
+		return err
+	}
+
+	sheet, err := makeLiveTeamStandingsSheet(newGatewayPool())
+	if err != nil {
+		return err
+	}
+	values, err := sheet.GetValues()
+	if err != nil {
+		return fmt.Errorf("error fetching sheet: %v", err)
+	}
+	snapshot := sheets.NewSnapshot(sheet.SpreadsheetID(), sheet.SheetName(), values)
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("Wrote %d rows to %s", len(values), *outPath)
+	return nil
+}
+
+func runMigrate(args []string, out io.Writer) error {
+	mydb, err := db.OpenDB(dbConnStr(), nil)
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	defer mydb.Close()
+
+	log.Info("Tables are up to date")
+	return nil
+}
+
+func runExport(args []string, out io.Writer) error {
+	mydb, err := db.OpenDB(dbConnStr(), nil)
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	defer mydb.Close()
+
+	return export(context.Background(), mydb, args, out)
+}
+
+func runQuery(args []string, out io.Writer) error {
+	mydb, err := db.OpenDB(dbConnStr(), nil)
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	defer mydb.Close()
+
+	return queryTeams(context.Background(), mydb, args, out)
+}
+
+// exportStore is what the export command reads from
+type exportStore interface {
+	db.Datastore
+	db.StandingsStore
+}
+
+var errUsage = errors.New("invalid usage, see help")
+
+// export dumps all teams or standings as JSON or CSV
+func export(ctx context.Context, store exportStore, args []string, out io.Writer) error {
+	if len(args) == 0 || (args[0] != "teams" && args[0] != "standings") {
+		return errUsage
+	}
+	what := args[0]
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "json", "json or csv")
+	outPath := flags.String("out", "", "file to write to instead of stdout")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+
+	teams, err := store.GetAllTeams(ctx, db.GetAllTeamsQuery{})
+	if err != nil {
+		return fmt.Errorf("error fetching teams: %v", err)
+	}
+
+	var data interface{} = teams
+	table := tabular.Teams(teams, nil)
+	if what == "standings" {
+		teamIDs := make([]string, len(teams))
+		for i, team := range teams {
+			teamIDs[i] = team.TeamID
+		}
+		standings, err := store.GetStandingsByTeamIDs(ctx, teamIDs)
+		if err != nil {
+			return fmt.Errorf("error fetching standings: %v", err)
+		}
+		if standings == nil {
+			standings = []models.Standing{}
+		}
+		data, table = standings, tabular.Standings(standings)
+	}
+
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "csv" {
+		return table.WriteCSV(out)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// queryTeams prints the teams matching the filters in args
+func queryTeams(ctx context.Context, store db.Datastore, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "teams" {
+		return errUsage
+	}
+
+	var query db.GetAllTeamsQuery
+	flags := flag.NewFlagSet("query teams", flag.ContinueOnError)
+	flags.Var((*stringsFlag)(&query.TeamIDs), "id", "team id")
+	flags.Var((*stringsFlag)(&query.Names), "name", "team name")
+	flags.Var((*stringsFlag)(&query.Franchises), "franchise", "franchise name")
+	flags.Var((*stringsFlag)(&query.Conferences), "conference", "conference name")
+	flags.Var((*stringsFlag)(&query.Tiers), "tier", "tier name")
+	flags.Var((*stringsFlag)(&query.Divisions), "division", "division name")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	teams, err := store.GetAllTeams(ctx, query)
+	if err == db.ErrInvalidTypeForQuery {
+		return errors.New("team ids must be integers")
+	} else if err != nil {
+		return fmt.Errorf("error fetching teams: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(teams)
+	}
+
+	table := tabular.Teams(teams, nil)
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(table.Header, "\t")))
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = tabular.CellString(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type exportStoreMock struct {
+	exportStore
+	teams     []models.Team
+	standings []models.Standing
+	query     db.GetAllTeamsQuery
+	err       error
+}
+
+func (m *exportStoreMock) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
+	m.query = query
+	return m.teams, m.err
+}
+
+func (m *exportStoreMock) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	return m.standings, nil
+}
+
+func Test_runCommand_unknown(t *testing.T) {
+	err := runCommand([]string{"nope"}, &bytes.Buffer{})
+	require.EqualError(t, err, "unknown command: nope")
+}
+
+func Test_queryTeams(t *testing.T) {
+	division := "Blue"
+	teams := []models.Team{
+		{TeamID: "1", Name: "Rockets", Franchise: "Space", Tier: "Elite", Conference: "Solar", Division: &division},
+		{TeamID: "2", Name: "Comets", Franchise: "Sky", Tier: "Elite", Conference: "Solar"},
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		err           error
+		expectedQuery db.GetAllTeamsQuery
+		expectedOut   string
+		expectedErr   string
+	}{
+		{
+			name: "filters",
+			args: []string{"teams", "--tier", "Elite", "--conference", "Solar", "--tier", "Major"},
+			expectedQuery: db.GetAllTeamsQuery{
+				Tiers:       []string{"Elite", "Major"},
+				Conferences: []string{"Solar"},
+			},
+			expectedOut: "ID  NAME     FRANCHISE  TIER   CONFERENCE  DIVISION\n" +
+				"1   Rockets  Space      Elite  Solar       Blue\n" +
+				"2   Comets   Sky        Elite  Solar       \n",
+		},
+		{
+			name:          "json",
+			args:          []string{"teams", "--id", "2", "--json"},
+			expectedQuery: db.GetAllTeamsQuery{TeamIDs: []string{"2"}},
+			expectedOut:   "[\n  {\n    \"id\": \"1\",\n    \"name\": \"Rockets\",\n    \"franchise\": \"Space\",\n    \"tier\": \"Elite\",\n    \"conference\": \"Solar\",\n    \"division\": \"Blue\"\n  },\n  {\n    \"id\": \"2\",\n    \"name\": \"Comets\",\n    \"franchise\": \"Sky\",\n    \"tier\": \"Elite\",\n    \"conference\": \"Solar\"\n  }\n]\n",
+		},
+		{
+			name:        "bad id",
+			args:        []string{"teams", "--id", "abc"},
+			err:         db.ErrInvalidTypeForQuery,
+			expectedErr: "team ids must be integers",
+		},
+		{
+			name:        "not teams",
+			args:        []string{"players"},
+			expectedErr: errUsage.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &exportStoreMock{teams: teams, err: test.err}
+			out := &bytes.Buffer{}
+
+			err := queryTeams(context.Background(), store, test.args, out)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedQuery, store.query)
+			require.Equal(t, test.expectedOut, out.String())
+		})
+	}
+}
+
+func Test_export(t *testing.T) {
+	store := &exportStoreMock{
+		teams: []models.Team{{TeamID: "1", Name: "Rockets", Franchise: "Space", Tier: "Elite", Conference: "Solar"}},
+		standings: []models.Standing{{
+			Team:             models.Team{TeamID: "1", Name: "Rockets", Franchise: "Space", Tier: "Elite", Conference: "Solar"},
+			OverallRecord:    models.Record{Wins: 3, Losses: 1},
+			ConferenceRecord: models.Record{Wins: 2, Losses: 1},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			name:        "teams csv",
+			args:        []string{"teams", "--format", "csv"},
+			expectedOut: "id,name,franchise,tier,conference,division\n1,Rockets,Space,Elite,Solar,\n",
+		},
+		{
+			name:        "standings json",
+			args:        []string{"standings"},
+			expectedOut: "[\n  {\n    \"team\": {\n      \"id\": \"1\",\n      \"name\": \"Rockets\",\n      \"franchise\": \"Space\",\n      \"tier\": \"Elite\",\n      \"conference\": \"Solar\"\n    },\n    \"overall_record\": {\n      \"wins\": 3,\n      \"losses\": 1\n    },\n    \"conference_record\": {\n      \"wins\": 2,\n      \"losses\": 1\n    },\n    \"series_record\": {\n      \"wins\": 0,\n      \"losses\": 0\n    },\n    \"win_percentage\": 0,\n    \"game_differential\": 0,\n    \"games_behind\": 0\n  }\n]\n",
+		},
+		{
+			name:        "bad format",
+			args:        []string{"teams", "--format", "xml"},
+			expectedErr: "unknown format: xml",
+		},
+		{
+			name:        "nothing to export",
+			args:        nil,
+			expectedErr: errUsage.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			err := export(context.Background(), store, test.args, out)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedOut, out.String())
+		})
+	}
+}
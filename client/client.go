@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyHeader is the header the api key is sent in
+	APIKeyHeader = "X-API-Key"
+
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	maxDelay          = 30 * time.Second
+)
+
+// Client calls the v1 API
+type Client struct {
+	// BaseURL is where the API is served, e.g. https://rsc.example.com
+	BaseURL string
+	// APIKey is sent with every request if set
+	APIKey     string
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request is retried after a 5xx or 429 response
+	MaxRetries int
+	// Backoff returns how long to wait before retry number attempt, starting at 1.
+	// A 429's Retry-After header is used instead when it's longer.
+	Backoff func(attempt int) time.Duration
+}
+
+// NewClient makes a client for the API at baseURL with the default retries and backoff
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: defaultMaxRetries,
+		Backoff:    ExponentialBackoff(defaultBaseDelay),
+	}
+}
+
+// ExponentialBackoff doubles the delay after base every attempt, with up to 50% jitter
+func ExponentialBackoff(base time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base << uint(attempt-1)
+		if delay <= 0 || delay > maxDelay {
+			delay = maxDelay
+		}
+		return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+}
+
+// Error is returned for any response that isn't a 2xx, with the message the API sent
+type Error struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is how long the API asked to wait before retrying, 0 if it didn't say
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rsc api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound checks if err is a 404 from the API
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (e *Error) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// envelope is the wrapper around every v1 response
+type envelope struct {
+	Data json.RawMessage `json:"data"`
+	Meta struct {
+		Total *int `json:"total"`
+		Page  *struct {
+			Offset int `json:"offset"`
+			Limit  int `json:"limit"`
+			Count  int `json:"count"`
+		} `json:"page"`
+		LastSync *time.Time `json:"last_sync"`
+		Season   string     `json:"season"`
+	} `json:"meta"`
+}
+
+// errorResp is the body of error responses
+type errorResp struct {
+	Error string `json:"error"`
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// get fetches a v1 path and decodes its envelope, retrying 5xx and 429 responses
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*envelope, error) {
+	var env envelope
+	if err := c.getJSON(ctx, path, query, &env); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// getJSON fetches a v1 path and decodes its body into v, retrying 5xx and 429 responses
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := c.BaseURL + "/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.doGet(ctx, u, v)
+		apiErr, ok := err.(*Error)
+		if !ok || !apiErr.retryable() || attempt >= c.MaxRetries {
+			return err
+		}
+
+		delay := time.Duration(0)
+		if c.Backoff != nil {
+			delay = c.Backoff(attempt + 1)
+		}
+		if apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// getPage fetches the page of a list starting at offset, with the most items the API allows, and decodes its data into v.
+// It returns the total number of items in the list, nil if the API didn't say.
+func (c *Client) getPage(ctx context.Context, path string, filter url.Values, offset int, v interface{}) (*int, error) {
+	query := url.Values{}
+	for k, vals := range filter {
+		query[k] = vals
+	}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(pageSize))
+
+	env, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		return nil, err
+	}
+	return env.Meta.Total, nil
+}
+
+func (c *Client) doGet(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set(APIKeyHeader, c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &Error{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(body)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		var errBody errorResp
+		if json.Unmarshal(body, &errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("rsc api: invalid response body: %v", err)
+	}
+	return nil
+}
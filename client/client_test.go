@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failFirst fails the first n requests with code before letting them through
+func failFirst(n, code int, calls *int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls++
+			if *calls <= n {
+				if code == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(code)
+				w.Write([]byte(`{"error":"try again"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func Test_Client_retries(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		code          int
+		maxRetries    int
+		expectedCalls int
+		expectedErr   *Error
+	}{
+		{
+			name:          "recovers from 5xx",
+			failures:      2,
+			code:          http.StatusServiceUnavailable,
+			maxRetries:    3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "recovers from 429",
+			failures:      1,
+			code:          http.StatusTooManyRequests,
+			maxRetries:    3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "gives up",
+			failures:      5,
+			code:          http.StatusInternalServerError,
+			maxRetries:    2,
+			expectedCalls: 3,
+			expectedErr:   &Error{StatusCode: http.StatusInternalServerError, Message: "try again"},
+		},
+		{
+			name:          "doesn't retry 4xx",
+			failures:      5,
+			code:          http.StatusUnauthorized,
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedErr:   &Error{StatusCode: http.StatusUnauthorized, Message: "try again"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			c := newTestServer(t, &storeMock{teams: makeTeams(1)}, failFirst(test.failures, test.code, &calls))
+			c.MaxRetries = test.maxRetries
+
+			team, err := c.GetTeam(context.Background(), "1")
+			require.Equal(t, test.expectedCalls, calls)
+			if test.expectedErr != nil {
+				require.Equal(t, test.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "1", team.TeamID)
+		})
+	}
+}
+
+func Test_Client_retryCanceled(t *testing.T) {
+	calls := 0
+	c := newTestServer(t, &storeMock{teams: makeTeams(1)}, failFirst(5, http.StatusBadGateway, &calls))
+	c.Backoff = func(int) time.Duration { return time.Hour }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.GetTeam(ctx, "1")
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 1, calls)
+}
+
+func Test_ExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100 * time.Millisecond)
+	for attempt, max := range []time.Duration{100, 200, 400, 800} {
+		delay := backoff(attempt + 1)
+		require.True(t, delay >= max*time.Millisecond/2 && delay <= max*time.Millisecond, "attempt %d: %s", attempt+1, delay)
+	}
+	require.True(t, backoff(100) <= maxDelay)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	require.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	require.Equal(t, time.Duration(0), parseRetryAfter(""))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	require.True(t, parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)) > 50*time.Second)
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// playersQuery gets the players of the teams matching the same filters as the /team route,
+// which doesn't include players
+const playersQuery = `query Players($ids: [ID!], $names: [String!], $franchises: [String!], $conferences: [String!], $tiers: [String!], $divisions: [String!]) {
+	teams(ids: $ids, names: $names, franchises: $franchises, conferences: $conferences, tiers: $tiers, divisions: $divisions) {
+		id
+		players { rscId name stats { goals assists saves shots } }
+	}
+}`
+
+// graphQLResp is the body of a GraphQL response
+type graphQLResp struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// query runs a GraphQL query and decodes its data into v. It's sent as a GET so it's retried and cached like the other reads.
+func (c *Client) query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	params := url.Values{"query": {query}}
+	if len(variables) > 0 {
+		raw, err := json.Marshal(variables)
+		if err != nil {
+			return err
+		}
+		params.Set("variables", string(raw))
+	}
+
+	var resp graphQLResp
+	if err := c.getJSON(ctx, "/graphql", params, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("rsc api: graphql: %s", strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(resp.Data, v)
+}
+
+// ListPlayers fetches the players of every team matching filter.
+// They're fetched with one GraphQL query, so very large filters can go over the API's query complexity limit.
+// The API doesn't sync players from any sheet yet, so until a player source is added this returns no players.
+func (c *Client) ListPlayers(ctx context.Context, filter TeamFilter) ([]models.Player, error) {
+	variables := map[string]interface{}{}
+	add := func(name string, vals []string) {
+		if len(vals) > 0 {
+			variables[name] = vals
+		}
+	}
+	add("ids", filter.IDs)
+	add("names", filter.Names)
+	add("franchises", filter.Franchises)
+	add("conferences", filter.Conferences)
+	add("tiers", filter.Tiers)
+	add("divisions", filter.Divisions)
+
+	var data struct {
+		Teams []struct {
+			ID      string `json:"id"`
+			Players []struct {
+				RSCID string       `json:"rscId"`
+				Name  string       `json:"name"`
+				Stats models.Stats `json:"stats"`
+			} `json:"players"`
+		} `json:"teams"`
+	}
+	if err := c.query(ctx, playersQuery, variables, &data); err != nil {
+		return nil, err
+	}
+
+	players := []models.Player{}
+	for _, team := range data.Teams {
+		for _, p := range team.Players {
+			players = append(players, models.Player{RSCID: p.RSCID, Name: p.Name, TeamID: team.ID, Stats: p.Stats})
+		}
+	}
+	return players, nil
+}
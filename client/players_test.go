@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListPlayers(t *testing.T) {
+	store := &storeMock{
+		teams: makeTeams(3),
+		players: []models.Player{
+			{RSCID: "RSC001", Name: "Ace", TeamID: "1", Stats: models.Stats{Goals: 10, Assists: 4, Saves: 7, Shots: 30}},
+			{RSCID: "RSC002", Name: "Bolt", TeamID: "2", Stats: models.Stats{Goals: 3}},
+			{RSCID: "RSC003", Name: "Comet", TeamID: "3", Stats: models.Stats{Saves: 12}},
+		},
+	}
+	c := newTestServer(t, store, nil)
+
+	tests := []struct {
+		name     string
+		filter   TeamFilter
+		expected []models.Player
+	}{
+		{
+			name:     "All players",
+			expected: store.players,
+		},
+		{
+			name:     "Filtered by tier",
+			filter:   TeamFilter{Tiers: []string{"Elite"}},
+			expected: []models.Player{store.players[0], store.players[2]},
+		},
+		{
+			name:     "No teams match",
+			filter:   TeamFilter{IDs: []string{"42"}},
+			expected: []models.Player{},
+		},
+	}
+
+	for _, test := range tests {
+		players, err := c.ListPlayers(context.Background(), test.filter)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expected, players, "%q wrong players", test.name)
+	}
+}
+
+func Test_ListPlayers_errors(t *testing.T) {
+	c := newTestServer(t, &storeMock{teams: makeTeams(3)}, nil)
+	_, err := c.ListPlayers(context.Background(), TeamFilter{IDs: []string{"abc"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rsc api: graphql: ")
+
+	c = newTestServer(t, &storeMock{}, func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"An API key is required"}`))
+		})
+	})
+	_, err = c.ListPlayers(context.Background(), TeamFilter{})
+	require.Equal(t, &Error{StatusCode: http.StatusForbidden, Message: "An API key is required"}, err)
+}
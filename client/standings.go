@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// ListStandings fetches the standings of every team matching filter, teams without one are left out
+func (c *Client) ListStandings(ctx context.Context, filter TeamFilter) ([]models.Standing, error) {
+	standings := []models.Standing{}
+	query := filter.values()
+	for {
+		var page []models.Standing
+		total, err := c.getPage(ctx, "/standings", query, len(standings), &page)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, page...)
+		if len(page) == 0 || total == nil || len(standings) >= *total {
+			return standings, nil
+		}
+	}
+}
+
+// GetStanding fetches the standing of a team by its id
+func (c *Client) GetStanding(ctx context.Context, teamID string) (*models.Standing, error) {
+	env, err := c.get(ctx, "/standings/"+url.PathEscape(teamID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var standing models.Standing
+	if err := json.Unmarshal(env.Data, &standing); err != nil {
+		return nil, err
+	}
+	return &standing, nil
+}
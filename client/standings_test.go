@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListStandings(t *testing.T) {
+	teams := makeTeams(3)
+	seed := 1
+	store := &storeMock{
+		teams: teams,
+		standings: []models.Standing{
+			{Team: teams[0], OverallRecord: models.Record{Wins: 1}, SeriesRecord: models.Record{Wins: 1}, WinPercentage: 1, Streak: "W1", Seed: &seed},
+			{Team: teams[2], OverallRecord: models.Record{Losses: 1}, WinPercentage: 0, GameDifferential: -3, GamesBehind: 1},
+		},
+	}
+	c := newTestServer(t, store, nil)
+
+	standings, err := c.ListStandings(context.Background(), TeamFilter{})
+	require.NoError(t, err)
+	require.Equal(t, store.standings, standings)
+
+	standings, err = c.ListStandings(context.Background(), TeamFilter{IDs: []string{"3"}})
+	require.NoError(t, err)
+	require.Equal(t, store.standings[1:], standings)
+}
+
+func Test_ListStandings_pages(t *testing.T) {
+	teams := makeTeams(1203)
+	store := &storeMock{teams: teams}
+	for _, team := range teams {
+		store.standings = append(store.standings, models.Standing{Team: team})
+	}
+	c := newTestServer(t, store, nil)
+
+	standings, err := c.ListStandings(context.Background(), TeamFilter{Tiers: []string{"Elite"}})
+	require.NoError(t, err)
+	require.Len(t, standings, 602)
+	require.Equal(t, "1203", standings[601].Team.TeamID)
+}
+
+func Test_GetStanding(t *testing.T) {
+	teams := makeTeams(2)
+	store := &storeMock{
+		teams:     teams,
+		standings: []models.Standing{{Team: teams[0], OverallRecord: models.Record{Wins: 5, Losses: 2}}},
+	}
+	c := newTestServer(t, store, nil)
+
+	standing, err := c.GetStanding(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, &store.standings[0], standing)
+
+	_, err = c.GetStanding(context.Background(), "2")
+	require.True(t, IsNotFound(err))
+	require.Equal(t, "Standing not found", err.(*Error).Message)
+
+	_, err = c.GetStanding(context.Background(), "abc")
+	require.Equal(t, &Error{StatusCode: http.StatusBadRequest, Message: "Team ID must be an integer"}, err)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// pageSize is how many items are asked for per page, the most the API allows
+const pageSize = 500
+
+// Team is a team with its standing, which is nil if it doesn't have one yet
+type Team struct {
+	models.Team
+	Standing *Standing `json:"standing"`
+}
+
+// Standing is the records of a team
+type Standing struct {
+	OverallRecord    models.Record  `json:"overall_record"`
+	ConferenceRecord models.Record  `json:"conference_record"`
+	DivisionRecord   *models.Record `json:"division_record,omitempty"`
+	SeriesRecord     models.Record  `json:"series_record"`
+	WinPercentage    float64        `json:"win_percentage"`
+	GameDifferential int            `json:"game_differential"`
+	GamesBehind      float64        `json:"games_behind"`
+	Streak           string         `json:"streak,omitempty"`
+	Seed             *int           `json:"seed,omitempty"`
+}
+
+// TeamFilter limits which teams are listed, teams must match one of the values of every field that's set
+type TeamFilter struct {
+	IDs         []string
+	Names       []string
+	Franchises  []string
+	Conferences []string
+	Tiers       []string
+	Divisions   []string
+}
+
+func (f TeamFilter) values() url.Values {
+	query := url.Values{}
+	add := func(key string, vals []string) {
+		for _, v := range vals {
+			query.Add(key, v)
+		}
+	}
+	add("id", f.IDs)
+	add("name", f.Names)
+	add("franchise", f.Franchises)
+	add("conference", f.Conferences)
+	add("tier", f.Tiers)
+	add("division", f.Divisions)
+	return query
+}
+
+// TeamIterator pages through teams, fetching the next page when the current one runs out
+type TeamIterator struct {
+	client *Client
+	ctx    context.Context
+	query  url.Values
+
+	page   []Team
+	cur    Team
+	offset int
+	done   bool
+	err    error
+}
+
+// Teams returns an iterator over every team matching filter
+func (c *Client) Teams(ctx context.Context, filter TeamFilter) *TeamIterator {
+	return &TeamIterator{client: c, ctx: ctx, query: filter.values()}
+}
+
+// Next moves to the next team, it returns false when there are none left or a request failed
+func (it *TeamIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Team is the team Next moved to
+func (it *TeamIterator) Team() Team {
+	return it.cur
+}
+
+// Err is the error that stopped the iterator, if any
+func (it *TeamIterator) Err() error {
+	return it.err
+}
+
+func (it *TeamIterator) fetch() {
+	var teams []Team
+	total, err := it.client.getPage(it.ctx, "/team", it.query, it.offset, &teams)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = teams
+	it.offset += len(teams)
+	if len(teams) == 0 || total == nil || it.offset >= *total {
+		it.done = true
+	}
+}
+
+// ListTeams fetches every team matching filter
+func (c *Client) ListTeams(ctx context.Context, filter TeamFilter) ([]Team, error) {
+	teams := []Team{}
+	it := c.Teams(ctx, filter)
+	for it.Next() {
+		teams = append(teams, it.Team())
+	}
+	return teams, it.Err()
+}
+
+// GetTeam fetches a team by its id
+func (c *Client) GetTeam(ctx context.Context, id string) (*Team, error) {
+	env, err := c.get(ctx, "/team/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var team Team
+	if err := json.Unmarshal(env.Data, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
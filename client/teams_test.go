@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/graph"
+	"github.com/mellena1/RSC-Spreadsheet-API/handler"
+	"github.com/stretchr/testify/require"
+)
+
+type storeMock struct {
+	db.Datastore
+	teams     []models.Team
+	standings []models.Standing
+	players   []models.Player
+}
+
+func matchesAny(vals []string, s string) bool {
+	if len(vals) == 0 {
+		return true
+	}
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *storeMock) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
+	for _, id := range query.TeamIDs {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, db.ErrInvalidTypeForQuery
+		}
+	}
+	teams := []models.Team{}
+	for _, team := range m.teams {
+		if matchesAny(query.TeamIDs, team.TeamID) && matchesAny(query.Tiers, team.Tier) &&
+			matchesAny(query.Conferences, team.Conference) && matchesAny(query.Franchises, team.Franchise) {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *storeMock) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	for _, id := range teamIDs {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, db.ErrInvalidTypeForQuery
+		}
+	}
+	var standings []models.Standing
+	for _, s := range m.standings {
+		if matchesAny(teamIDs, s.Team.TeamID) {
+			standings = append(standings, s)
+		}
+	}
+	return standings, nil
+}
+
+func (m *storeMock) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	var players []models.Player
+	for _, p := range m.players {
+		if matchesAny(teamIDs, p.TeamID) {
+			players = append(players, p)
+		}
+	}
+	return players, nil
+}
+
+func (m *storeMock) GetAllStandings(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Standing, error) {
+	teams, err := m.GetAllTeams(ctx, query)
+	if err != nil || len(teams) == 0 {
+		return nil, err
+	}
+	teamIDs := make([]string, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.TeamID
+	}
+	return m.GetStandingsByTeamIDs(ctx, teamIDs)
+}
+
+// newTestServer serves the real v1 team, standings and graphql routes backed by store
+func newTestServer(t *testing.T, store *storeMock, wrap func(http.Handler) http.Handler) *Client {
+	router := mux.NewRouter()
+	v1 := handler.APIVersion{Name: "v1"}
+	subR := router.PathPrefix(v1.Prefix() + "/team").Subrouter()
+	subR.Use(v1.Middleware)
+	(&handler.TeamHandler{DB: store, Standings: store}).AddRoutes(subR)
+	subR = router.PathPrefix(v1.Prefix() + "/standings").Subrouter()
+	subR.Use(v1.Middleware)
+	(&handler.StandingsHandler{DB: store}).AddRoutes(subR)
+	schema, err := graph.NewSchema(store, 6)
+	require.NoError(t, err)
+	subR = router.PathPrefix(v1.Prefix() + "/graphql").Subrouter()
+	subR.Use(v1.Middleware)
+	(&handler.GraphQLHandler{Schema: schema}).AddRoutes(subR)
+
+	var h http.Handler = router
+	if wrap != nil {
+		h = wrap(h)
+	}
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	c := NewClient(server.URL, "")
+	c.Backoff = nil
+	return c
+}
+
+func makeTeams(n int) []models.Team {
+	teams := make([]models.Team, n)
+	for i := range teams {
+		tier := "Elite"
+		if i%2 == 1 {
+			tier = "Major"
+		}
+		teams[i] = models.Team{TeamID: strconv.Itoa(i + 1), Name: "Team " + strconv.Itoa(i+1), Franchise: "F", Tier: tier, Conference: "Solar"}
+	}
+	return teams
+}
+
+func Test_ListTeams(t *testing.T) {
+	store := &storeMock{teams: makeTeams(1203)}
+	c := newTestServer(t, store, nil)
+
+	tests := []struct {
+		name          string
+		filter        TeamFilter
+		expectedCount int
+		expectedLast  string
+	}{
+		{
+			name:          "every page",
+			expectedCount: 1203,
+			expectedLast:  "1203",
+		},
+		{
+			name:          "filtered",
+			filter:        TeamFilter{Tiers: []string{"Major"}},
+			expectedCount: 601,
+			expectedLast:  "1202",
+		},
+		{
+			name:          "no matches",
+			filter:        TeamFilter{Conferences: []string{"Lunar"}},
+			expectedCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teams, err := c.ListTeams(context.Background(), test.filter)
+			require.NoError(t, err)
+			require.Len(t, teams, test.expectedCount)
+			if test.expectedCount > 0 {
+				require.Equal(t, test.expectedLast, teams[len(teams)-1].TeamID)
+			}
+		})
+	}
+}
+
+func Test_GetTeam(t *testing.T) {
+	store := &storeMock{
+		teams: makeTeams(2),
+		standings: []models.Standing{{
+			Team:             makeTeams(1)[0],
+			OverallRecord:    models.Record{Wins: 5, Losses: 2},
+			ConferenceRecord: models.Record{Wins: 3, Losses: 1},
+		}},
+	}
+	c := newTestServer(t, store, nil)
+
+	team, err := c.GetTeam(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, "Team 1", team.Name)
+	require.Equal(t, &Standing{
+		OverallRecord:    models.Record{Wins: 5, Losses: 2},
+		ConferenceRecord: models.Record{Wins: 3, Losses: 1},
+	}, team.Standing)
+
+	team, err = c.GetTeam(context.Background(), "2")
+	require.NoError(t, err)
+	require.Nil(t, team.Standing)
+
+	_, err = c.GetTeam(context.Background(), "10")
+	require.True(t, IsNotFound(err))
+	require.Equal(t, "Team not found", err.(*Error).Message)
+
+	_, err = c.GetTeam(context.Background(), "abc")
+	require.Equal(t, &Error{StatusCode: http.StatusBadRequest, Message: "Team ID must be an integer"}, err)
+}
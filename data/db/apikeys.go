@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// ErrAPIKeyNotFound is returned if a given api key doesn't exist
+var ErrAPIKeyNotFound error = errors.New("API key not found")
+
+// APIKeyStore holds the API keys allowed to use the API
+type APIKeyStore interface {
+	GetAPIKey(ctx context.Context, key string) (models.APIKey, error)
+	CreateAPIKey(ctx context.Context, name, owner string, rateLimit *int) (string, models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+}
+
+// hashAPIKey hashes a key so the raw keys never have to be stored
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GetAPIKey looks up the key, returning ErrAPIKeyNotFound if it doesn't exist
+func (db *DB) GetAPIKey(ctx context.Context, key string) (models.APIKey, error) {
+	defer metrics.ObserveDBQuery("GetAPIKey", time.Now())
+	log := logging.FromContext(ctx)
+
+	apiKey := models.APIKey{}
+	err := db.sqlDB.QueryRowContext(ctx, `
+		SELECT api_key_id, name, owner, rate_limit, created_at, revoked_at FROM api_key WHERE key_hash=$1;
+	`, hashAPIKey(key)).Scan(&apiKey.ID, &apiKey.Name, &apiKey.Owner, &apiKey.RateLimit, &apiKey.CreatedAt, &apiKey.RevokedAt)
+	if err == sql.ErrNoRows {
+		return apiKey, ErrAPIKeyNotFound
+	} else if err != nil {
+		log.Errorf("Error getting api key from db: %v", err)
+		return apiKey, err
+	}
+
+	return apiKey, nil
+}
+
+// CreateAPIKey makes a new key, returning the raw key. Only its hash is stored so it can't be retrieved again.
+func (db *DB) CreateAPIKey(ctx context.Context, name, owner string, rateLimit *int) (string, models.APIKey, error) {
+	defer metrics.ObserveDBQuery("CreateAPIKey", time.Now())
+	log := logging.FromContext(ctx)
+
+	key, err := newAPIKey()
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+
+	apiKey := models.APIKey{Name: name, Owner: owner, RateLimit: rateLimit}
+	err = db.sqlDB.QueryRowContext(ctx, `
+		INSERT INTO api_key (key_hash, name, owner, rate_limit)
+		VALUES($1,$2,$3,$4) RETURNING api_key_id, created_at;
+	`, hashAPIKey(key), name, owner, rateLimit).Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		log.Errorf("Error inserting api key into db: %v", err)
+		return "", apiKey, err
+	}
+
+	return key, apiKey, nil
+}
+
+// RevokeAPIKey revokes a key so it can no longer be used, returning ErrAPIKeyNotFound if it doesn't exist
+func (db *DB) RevokeAPIKey(ctx context.Context, id int) error {
+	defer metrics.ObserveDBQuery("RevokeAPIKey", time.Now())
+	log := logging.FromContext(ctx)
+
+	result, err := db.sqlDB.ExecContext(ctx, `
+		UPDATE api_key SET revoked_at=now() WHERE api_key_id=$1 AND revoked_at IS NULL;
+	`, id)
+	if err != nil {
+		log.Errorf("Error revoking api key: %v", err)
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
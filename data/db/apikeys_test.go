@@ -0,0 +1,21 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hashAPIKey(t *testing.T) {
+	require.Equal(t, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", hashAPIKey("foo"))
+}
+
+func Test_newAPIKey(t *testing.T) {
+	a, err := newAPIKey()
+	require.NoError(t, err)
+	require.Len(t, a, 48)
+
+	b, err := newAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+}
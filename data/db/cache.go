@@ -0,0 +1,191 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+const (
+	datastoreCacheName = "datastore"
+	standingsCacheName = "standings"
+)
+
+type cacheEntry struct {
+	key   string
+	value interface{}
+}
+
+// lruCache holds up to maxEntries values, evicting the least recently used, and is emptied by Clear.
+// Values have to be copied by the caller, they're shared with every later hit.
+type lruCache struct {
+	name       string
+	maxEntries int
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	generation uint64
+}
+
+func newLRUCache(name string, maxEntries int) *lruCache {
+	return &lruCache{
+		name:       name,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// get returns the value for key if it's cached. Otherwise it returns the generation to store the fetched value with.
+func (c *lruCache) get(key string) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		metrics.CacheHit(c.name)
+		return elem.Value.(*cacheEntry).value, c.generation, true
+	}
+	metrics.CacheMiss(c.name)
+	return nil, c.generation, false
+}
+
+// store adds an entry, unless the cache was cleared since the value was fetched
+func (c *lruCache) store(key string, generation uint64, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	metrics.SetCacheEntries(c.name, c.order.Len())
+}
+
+// Clear empties the cache. Values fetched before the clear won't be stored.
+func (c *lruCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+	metrics.SetCacheEntries(c.name, 0)
+}
+
+// Len returns the number of cached values
+func (c *lruCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// CachedDatastore is a read-through cache in front of a Datastore.
+// It holds up to maxEntries results, evicting the least recently used, and is emptied by Clear.
+type CachedDatastore struct {
+	*lruCache
+	next Datastore
+}
+
+// NewCachedDatastore makes a cache in front of next holding up to maxEntries results
+func NewCachedDatastore(next Datastore, maxEntries int) *CachedDatastore {
+	return &CachedDatastore{lruCache: newLRUCache(datastoreCacheName, maxEntries), next: next}
+}
+
+// normalizeValues sorts and dedupes query values so equivalent queries share a cache key
+func normalizeValues(vals []string) string {
+	sorted := append([]string{}, vals...)
+	sort.Strings(sorted)
+
+	deduped := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			deduped = append(deduped, v)
+		}
+	}
+	return strings.Join(deduped, "\x1f")
+}
+
+func (q GetAllTeamsQuery) cacheKey() string {
+	return strings.Join([]string{
+		"teams",
+		normalizeValues(q.TeamIDs),
+		normalizeValues(q.Names),
+		normalizeValues(q.Franchises),
+		normalizeValues(q.Conferences),
+		normalizeValues(q.Tiers),
+		normalizeValues(q.Divisions),
+	}, "\x1e")
+}
+
+// GetAllTeams returns the cached result for the query, or gets it from the underlying Datastore
+func (c *CachedDatastore) GetAllTeams(ctx context.Context, query GetAllTeamsQuery) ([]models.Team, error) {
+	key := query.cacheKey()
+	cached, generation, ok := c.get(key)
+	if ok {
+		return append([]models.Team{}, cached.([]models.Team)...), nil
+	}
+
+	teams, err := c.next.GetAllTeams(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, generation, append([]models.Team{}, teams...))
+	return teams, nil
+}
+
+// CachedStandingsStore is a read-through cache in front of a StandingsStore, working like CachedDatastore
+type CachedStandingsStore struct {
+	*lruCache
+	next StandingsStore
+}
+
+// NewCachedStandingsStore makes a cache in front of next holding up to maxEntries results
+func NewCachedStandingsStore(next StandingsStore, maxEntries int) *CachedStandingsStore {
+	return &CachedStandingsStore{lruCache: newLRUCache(standingsCacheName, maxEntries), next: next}
+}
+
+func (c *CachedStandingsStore) cached(key string, fetch func() ([]models.Standing, error)) ([]models.Standing, error) {
+	cached, generation, ok := c.get(key)
+	if ok {
+		return append([]models.Standing{}, cached.([]models.Standing)...), nil
+	}
+
+	standings, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, generation, append([]models.Standing{}, standings...))
+	return standings, nil
+}
+
+// GetAllStandings returns the cached result for the query, or gets it from the underlying StandingsStore
+func (c *CachedStandingsStore) GetAllStandings(ctx context.Context, query GetAllTeamsQuery) ([]models.Standing, error) {
+	return c.cached("all\x1e"+query.cacheKey(), func() ([]models.Standing, error) {
+		return c.next.GetAllStandings(ctx, query)
+	})
+}
+
+// GetStandingsByTeamIDs returns the cached result for the team IDs, or gets it from the underlying StandingsStore
+func (c *CachedStandingsStore) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	return c.cached("ids\x1e"+normalizeValues(teamIDs), func() ([]models.Standing, error) {
+		return c.next.GetStandingsByTeamIDs(ctx, teamIDs)
+	})
+}
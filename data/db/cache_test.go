@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type countingDatastore struct {
+	calls int
+	err   error
+	// during is called while the query is running
+	during func()
+}
+
+func (c *countingDatastore) GetAllTeams(ctx context.Context, query GetAllTeamsQuery) ([]models.Team, error) {
+	c.calls++
+	if c.during != nil {
+		c.during()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []models.Team{{TeamID: "1", Name: query.Names[0]}}, nil
+}
+
+func Test_normalizeValues(t *testing.T) {
+	require.Equal(t, normalizeValues([]string{"b", "a", "b"}), normalizeValues([]string{"a", "b"}))
+	require.NotEqual(t, normalizeValues([]string{"a b"}), normalizeValues([]string{"a", "b"}))
+	require.Equal(t, "", normalizeValues(nil))
+}
+
+func Test_GetAllTeamsQuery_cacheKey(t *testing.T) {
+	a := GetAllTeamsQuery{Names: []string{"A", "B"}, Tiers: []string{"Master"}}
+	b := GetAllTeamsQuery{Names: []string{"B", "A", "A"}, Tiers: []string{"Master"}}
+	c := GetAllTeamsQuery{Franchises: []string{"A", "B"}, Tiers: []string{"Master"}}
+
+	require.Equal(t, a.cacheKey(), b.cacheKey())
+	require.NotEqual(t, a.cacheKey(), c.cacheKey())
+}
+
+func Test_CachedDatastore(t *testing.T) {
+	ctx := context.Background()
+	next := &countingDatastore{}
+	cache := NewCachedDatastore(next, 2)
+
+	teams, err := cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"A"}})
+	require.NoError(t, err)
+	require.Equal(t, "A", teams[0].Name)
+	_, err = cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"A", "A"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, next.calls)
+
+	// evicts least recently used
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"B"}})
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"A"}})
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"C"}})
+	require.Equal(t, 2, cache.Len())
+	require.Equal(t, 3, next.calls)
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"A"}})
+	require.Equal(t, 3, next.calls)
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"B"}})
+	require.Equal(t, 4, next.calls)
+
+	cache.Clear()
+	require.Equal(t, 0, cache.Len())
+	cache.GetAllTeams(ctx, GetAllTeamsQuery{Names: []string{"A"}})
+	require.Equal(t, 5, next.calls)
+}
+
+func Test_CachedDatastore_ClearDuringQuery(t *testing.T) {
+	next := &countingDatastore{}
+	cache := NewCachedDatastore(next, 10)
+	next.during = cache.Clear
+
+	cache.GetAllTeams(context.Background(), GetAllTeamsQuery{Names: []string{"A"}})
+
+	require.Equal(t, 0, cache.Len())
+}
+
+func Test_CachedDatastore_ErrorsNotCached(t *testing.T) {
+	next := &countingDatastore{err: errors.New("db down")}
+	cache := NewCachedDatastore(next, 10)
+
+	_, err := cache.GetAllTeams(context.Background(), GetAllTeamsQuery{Names: []string{"A"}})
+
+	require.EqualError(t, err, "db down")
+	require.Equal(t, 0, cache.Len())
+}
+
+type countingStandingsStore struct {
+	allCalls int
+	idsCalls int
+}
+
+func (c *countingStandingsStore) GetAllStandings(ctx context.Context, query GetAllTeamsQuery) ([]models.Standing, error) {
+	c.allCalls++
+	return []models.Standing{{Team: models.Team{TeamID: "1", Tier: query.Tiers[0]}}}, nil
+}
+
+func (c *countingStandingsStore) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	c.idsCalls++
+	return []models.Standing{{Team: models.Team{TeamID: teamIDs[0]}}}, nil
+}
+
+func Test_CachedStandingsStore(t *testing.T) {
+	ctx := context.Background()
+	next := &countingStandingsStore{}
+	cache := NewCachedStandingsStore(next, 10)
+
+	standings, err := cache.GetAllStandings(ctx, GetAllTeamsQuery{Tiers: []string{"Master"}})
+	require.NoError(t, err)
+	require.Equal(t, "Master", standings[0].Team.Tier)
+	standings, err = cache.GetAllStandings(ctx, GetAllTeamsQuery{Tiers: []string{"Master", "Master"}})
+	require.NoError(t, err)
+	require.Equal(t, "Master", standings[0].Team.Tier)
+	require.Equal(t, 1, next.allCalls)
+
+	// a hit can't change what later hits get
+	standings[0].Team.Tier = "Elite"
+	standings, _ = cache.GetAllStandings(ctx, GetAllTeamsQuery{Tiers: []string{"Master"}})
+	require.Equal(t, "Master", standings[0].Team.Tier)
+
+	standings, err = cache.GetStandingsByTeamIDs(ctx, []string{"2", "1"})
+	require.NoError(t, err)
+	require.Equal(t, "2", standings[0].Team.TeamID)
+	cache.GetStandingsByTeamIDs(ctx, []string{"1", "2"})
+	require.Equal(t, 1, next.idsCalls)
+	require.Equal(t, 2, cache.Len())
+
+	cache.Clear()
+	require.Equal(t, 0, cache.Len())
+	cache.GetAllStandings(ctx, GetAllTeamsQuery{Tiers: []string{"Master"}})
+	require.Equal(t, 2, next.allCalls)
+}
@@ -1,46 +1,86 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
-	"github.com/mellena1/RSC-Spreadsheet-API/data/sheets"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
 	log "github.com/sirupsen/logrus"
 
 	_ "github.com/lib/pq"
 )
 
 type Datastore interface {
-	GetAllTeams(GetAllTeamsQuery) ([]models.Team, error)
+	GetAllTeams(context.Context, GetAllTeamsQuery) ([]models.Team, error)
 }
 
+// StatusReporter reports on the health of the db and its data sources
+type StatusReporter interface {
+	Ping() error
+	LastSync() time.Time
+	TableRowCounts() (map[string]int, error)
+	DataSources() []string
+}
+
+// tables is every table the db creates, used for reporting row counts
+var tables = []string{"team", "team_standing", "player", "api_key", "sync_run", "webhook_subscription", "webhook_delivery", "event"}
+
 type DB struct {
 	sqlDB *sql.DB
 
-	teamStandingsUpdater sheets.TeamStandingsRetriever
+	// sources are synced in this order, every source comes after its dependencies
+	sources []Source
+
+	syncMu     sync.RWMutex
+	lastSync   time.Time
+	lastChange time.Time
+
+	// syncRunMu makes sure only one sync runs at a time
+	syncRunMu sync.Mutex
+	// syncListeners are called every time a sync commits
+	syncListeners []syncListener
+	// eventListeners are called with the changes found by every sync
+	eventListeners []func([]models.Event)
 }
 
-func NewDB(connStr string, teamStandingsSheet sheets.TeamStandingsRetriever) (*DB, error) {
+// OpenDB connects to the db and makes any missing tables, without syncing.
+// sources can be nil if the DB won't be synced.
+func OpenDB(connStr string, sources *Registry) (*DB, error) {
+	var ordered []Source
+	if sources != nil {
+		var err error
+		if ordered, err = sources.Ordered(); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, err
 	}
 
 	if err = db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	newdb := &DB{
 		sqlDB: db,
 
-		teamStandingsUpdater: teamStandingsSheet,
+		sources: ordered,
 	}
 
 	if err = newdb.makeTablesIfNotExist(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	if err = newdb.fillTeamData(); err != nil {
+	if err = newdb.loadLastSync(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -51,6 +91,65 @@ func (db *DB) Close() error {
 	return db.sqlDB.Close()
 }
 
+// Ping checks that the db connection is still alive
+func (db *DB) Ping() error {
+	return db.sqlDB.Ping()
+}
+
+// LastSync returns the time of the last successful sheet sync, zero if there hasn't been one
+func (db *DB) LastSync() time.Time {
+	db.syncMu.RLock()
+	defer db.syncMu.RUnlock()
+	return db.lastSync
+}
+
+// LastChange returns when a sync last wrote new data, zero if none has. Syncs that found
+// nothing changed don't move it, so it's what responses are cached by.
+func (db *DB) LastChange() time.Time {
+	db.syncMu.RLock()
+	defer db.syncMu.RUnlock()
+	return db.lastChange
+}
+
+func (db *DB) setLastChange(t time.Time) {
+	db.syncMu.Lock()
+	defer db.syncMu.Unlock()
+	db.lastChange = t
+}
+
+func (db *DB) setLastSync(t time.Time) {
+	db.syncMu.Lock()
+	defer db.syncMu.Unlock()
+	db.lastSync = t
+	metrics.SetLastSync(t)
+}
+
+// TableRowCounts returns the number of rows in each table
+func (db *DB) TableRowCounts() (map[string]int, error) {
+	counts := make(map[string]int, len(tables))
+	for _, table := range tables {
+		var count int
+		err := db.sqlDB.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s;", table)).Scan(&count)
+		if err != nil {
+			log.Errorf("Error counting rows in %s: %v", table, err)
+			return nil, err
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// DataSources returns the spreadsheet IDs the db pulls its data from
+func (db *DB) DataSources() []string {
+	ids := []string{}
+	for _, source := range db.sources {
+		if source.SpreadsheetID != "" && !containsString(ids, source.SpreadsheetID) {
+			ids = append(ids, source.SpreadsheetID)
+		}
+	}
+	return ids
+}
+
 func (db *DB) makeTablesIfNotExist() error {
 	tx, err := db.sqlDB.Begin()
 	if err != nil {
@@ -74,30 +173,145 @@ func (db *DB) makeTablesIfNotExist() error {
 		return err
 	}
 
-	return tx.Commit()
-}
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS team_standing (
+			team_id integer PRIMARY KEY REFERENCES team(team_id),
+			overall_wins integer NOT NULL,
+			overall_losses integer NOT NULL,
+			conference_wins integer NOT NULL,
+			conference_losses integer NOT NULL,
+			division_wins integer,
+			division_losses integer,
+			series_wins integer NOT NULL DEFAULT 0,
+			series_losses integer NOT NULL DEFAULT 0,
+			win_percentage double precision NOT NULL DEFAULT 0,
+			game_differential integer NOT NULL DEFAULT 0,
+			games_behind double precision NOT NULL DEFAULT 0,
+			streak text,
+			seed integer
+		);
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS series_wins integer NOT NULL DEFAULT 0;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS series_losses integer NOT NULL DEFAULT 0;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS win_percentage double precision NOT NULL DEFAULT 0;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS game_differential integer NOT NULL DEFAULT 0;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS games_behind double precision NOT NULL DEFAULT 0;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS streak text;
+		ALTER TABLE team_standing ADD COLUMN IF NOT EXISTS seed integer;
+	`)
+	if err != nil {
+		log.Errorf("Failed to make team_standing table: %v", err)
+		tx.Rollback()
+		return err
+	}
 
-func (db *DB) fillTeamData() error {
-	teamData, err := db.teamStandingsUpdater.GetTeamStandingsFromSheet()
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS player (
+			rsc_id text PRIMARY KEY,
+			name text NOT NULL,
+			team_id integer REFERENCES team(team_id),
+			goals integer NOT NULL DEFAULT 0,
+			assists integer NOT NULL DEFAULT 0,
+			saves integer NOT NULL DEFAULT 0,
+			shots integer NOT NULL DEFAULT 0
+		);
+		-- players stay when their team is removed from the standings, they're just no longer on a team
+		ALTER TABLE player DROP CONSTRAINT IF EXISTS player_team_id_fkey;
+		ALTER TABLE player ADD CONSTRAINT player_team_id_fkey FOREIGN KEY (team_id) REFERENCES team(team_id) ON DELETE SET NULL;
+	`)
 	if err != nil {
+		log.Errorf("Failed to make player table: %v", err)
+		tx.Rollback()
 		return err
 	}
 
-	tx, err := db.sqlDB.Begin()
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS api_key (
+			api_key_id SERIAL PRIMARY KEY,
+			key_hash text NOT NULL UNIQUE,
+			name text NOT NULL,
+			owner text NOT NULL,
+			rate_limit integer,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			revoked_at timestamptz
+		);
+	`)
 	if err != nil {
+		log.Errorf("Failed to make api_key table: %v", err)
+		tx.Rollback()
 		return err
 	}
 
-	for _, t := range teamData {
-		_, err = tx.Exec(`
-			INSERT INTO team (name, franchise, conference, tier, division) 
-			VALUES($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING;
-		`, t.Team.Name, t.Team.Franchise, t.Team.Conference, t.Team.Tier, t.Team.Division)
-		if err != nil {
-			log.Errorf("Failed to insert team into team table: %v", err)
-			tx.Rollback()
-			return err
-		}
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS sync_run (
+			sync_run_id SERIAL PRIMARY KEY,
+			source text NOT NULL,
+			started_at timestamptz NOT NULL,
+			duration_ms bigint NOT NULL,
+			rows_fetched integer NOT NULL,
+			added integer NOT NULL,
+			changed integer NOT NULL DEFAULT 0,
+			removed integer NOT NULL DEFAULT 0,
+			unchanged integer NOT NULL,
+			content_hash text,
+			skipped boolean NOT NULL DEFAULT false,
+			error text
+		);
+		ALTER TABLE sync_run ADD COLUMN IF NOT EXISTS content_hash text;
+		ALTER TABLE sync_run ADD COLUMN IF NOT EXISTS skipped boolean NOT NULL DEFAULT false;
+		ALTER TABLE sync_run ADD COLUMN IF NOT EXISTS changed integer NOT NULL DEFAULT 0;
+		ALTER TABLE sync_run ADD COLUMN IF NOT EXISTS removed integer NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		log.Errorf("Failed to make sync_run table: %v", err)
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_subscription (
+			webhook_subscription_id SERIAL PRIMARY KEY,
+			url text NOT NULL,
+			secret text NOT NULL,
+			event_types text[] NOT NULL,
+			created_at timestamptz NOT NULL DEFAULT now()
+		);
+	`)
+	if err != nil {
+		log.Errorf("Failed to make webhook_subscription table: %v", err)
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_delivery (
+			webhook_delivery_id SERIAL PRIMARY KEY,
+			webhook_subscription_id integer NOT NULL REFERENCES webhook_subscription(webhook_subscription_id) ON DELETE CASCADE,
+			event_type text NOT NULL,
+			attempt integer NOT NULL,
+			status_code integer,
+			error text,
+			success boolean NOT NULL,
+			delivered_at timestamptz NOT NULL
+		);
+	`)
+	if err != nil {
+		log.Errorf("Failed to make webhook_delivery table: %v", err)
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS event (
+			event_id BIGSERIAL PRIMARY KEY,
+			type text NOT NULL,
+			payload jsonb NOT NULL,
+			created_at timestamptz NOT NULL
+		);
+	`)
+	if err != nil {
+		log.Errorf("Failed to make event table: %v", err)
+		tx.Rollback()
+		return err
 	}
 
 	return tx.Commit()
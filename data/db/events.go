@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// EventStore holds the log of every change found by syncs
+type EventStore interface {
+	GetEventsSince(ctx context.Context, lastID int64, limit int) ([]models.Event, error)
+}
+
+// insertEvents adds events to the event log, setting their IDs
+func insertEvents(ctx context.Context, tx *sql.Tx, events []models.Event) error {
+	for i := range events {
+		payload, err := json.Marshal(&events[i])
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO event (type, payload, created_at) VALUES($1,$2,$3) RETURNING event_id;
+		`, events[i].Type, payload, events[i].CreatedAt).Scan(&events[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEventsSince returns up to limit events after lastID, oldest first
+func (db *DB) GetEventsSince(ctx context.Context, lastID int64, limit int) ([]models.Event, error) {
+	defer metrics.ObserveDBQuery("GetEventsSince", time.Now())
+	log := logging.FromContext(ctx)
+
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT event_id, payload FROM event WHERE event_id > $1 ORDER BY event_id LIMIT $2;
+	`, lastID, limit)
+	if err != nil {
+		log.Errorf("Error getting events from db: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var id int64
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			log.Errorf("Error scanning an event: %s", err)
+			return nil, err
+		}
+		event := models.Event{}
+		if err := json.Unmarshal(payload, &event); err != nil {
+			log.Errorf("Error unmarshaling event %d: %s", id, err)
+			return nil, err
+		}
+		event.ID = id
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// GetPlayersByTeamIDs returns the players on all of the given teams in one query.
+// No source writes the player table yet, so it's empty unless players are added to the db by hand.
+func (db *DB) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	defer metrics.ObserveDBQuery("GetPlayersByTeamIDs", time.Now())
+	log := logging.FromContext(ctx)
+
+	ids, err := teamIDsToInts(teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT rsc_id, name, team_id, goals, assists, saves, shots
+		FROM player WHERE team_id = ANY($1) ORDER BY name;
+	`, pq.Array(ids))
+	if err != nil {
+		log.Errorf("Error getting players from db: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []models.Player{}
+	for rows.Next() {
+		p := models.Player{}
+		err := rows.Scan(&p.RSCID, &p.Name, &p.TeamID, &p.Stats.Goals, &p.Stats.Assists, &p.Stats.Saves, &p.Stats.Shots)
+		if err != nil {
+			log.Errorf("Error scanning a player: %s", err)
+			return nil, err
+		}
+		players = append(players, p)
+	}
+
+	return players, nil
+}
@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+)
+
+// Source is a data source the db syncs, like a tab of the RSC spreadsheet
+type Source struct {
+	Name string
+	// SpreadsheetID is the spreadsheet the source reads from, reported as one of the db's data sources
+	SpreadsheetID string
+	// SheetName is the tab the source reads, used to label its metrics
+	SheetName string
+	// Schedule is how often the source is synced in the background, zero only syncs it on demand
+	Schedule time.Duration
+	// Tables are the tables the source writes to. SyncTx only writes to these,
+	// and only the caches reading from them are cleared when the source syncs.
+	Tables []string
+	// DependsOn are the sources that have to be synced first, like the source that makes the teams this one references
+	DependsOn []string
+	// Fetch gets the rows of the source and how many there are.
+	// They're only written if they changed since the last successful sync.
+	Fetch func(ctx context.Context) (rows interface{}, count int, err error)
+	// Write writes the fetched rows in tx, filling in the counts of run, and returns the changes it found.
+	// The changes are stored as events in the same transaction, which is committed after Write returns.
+	Write func(ctx context.Context, tx *SyncTx, rows interface{}, run *models.SyncRun) ([]models.Event, error)
+}
+
+// Registry holds the sources a db syncs
+type Registry struct {
+	sources map[string]Source
+	// names are the names of the sources in the order they were registered
+	names []string
+}
+
+// NewRegistry makes an empty source registry
+func NewRegistry() *Registry {
+	return &Registry{sources: map[string]Source{}}
+}
+
+// Register adds a source, its name has to be unique and every table it writes to has to be one the db makes
+func (r *Registry) Register(source Source) error {
+	if source.Name == "" {
+		return fmt.Errorf("source must have a name")
+	}
+	if source.Fetch == nil || source.Write == nil {
+		return fmt.Errorf("source %s must have fetch and write funcs", source.Name)
+	}
+	if _, ok := r.sources[source.Name]; ok {
+		return fmt.Errorf("source %s is already registered", source.Name)
+	}
+	for _, table := range source.Tables {
+		if !containsString(tables, table) {
+			return fmt.Errorf("source %s writes to unknown table %s", source.Name, table)
+		}
+	}
+
+	r.sources[source.Name] = source
+	r.names = append(r.names, source.Name)
+	return nil
+}
+
+// Ordered returns the sources with every source after its dependencies, otherwise keeping the order they were registered in.
+// It fails if a source depends on one that isn't registered or the dependencies have a cycle.
+func (r *Registry) Ordered() ([]Source, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(r.names))
+	ordered := make([]Source, 0, len(r.names))
+
+	var visit func(name string, from string) error
+	visit = func(name string, from string) error {
+		source, ok := r.sources[name]
+		if !ok {
+			return fmt.Errorf("source %s depends on unknown source %s", from, name)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("sources %s and %s depend on each other", from, name)
+		}
+
+		state[name] = visiting
+		for _, dep := range source.DependsOn {
+			if err := visit(dep, name); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, source)
+		return nil
+	}
+
+	for _, name := range r.names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// SyncTx is the transaction a source writes its rows in, it can only write to the source's tables
+type SyncTx struct {
+	tx     *sql.Tx
+	source Source
+}
+
+// QueryContext runs a read query in the transaction, like getting the stored rows to compare with the fetched ones
+func (t *SyncTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+func (t *SyncTx) checkTable(table string) error {
+	if !containsString(t.source.Tables, table) {
+		return fmt.Errorf("source %s can't write to %s, it isn't one of its tables", t.source.Name, table)
+	}
+	return nil
+}
+
+// DeleteAll empties table, for sources that replace every row on each sync
+func (t *SyncTx) DeleteAll(ctx context.Context, table string) error {
+	if err := t.checkTable(table); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s;", pq.QuoteIdentifier(table)))
+	return err
+}
+
+// DeleteExcept deletes the rows of table whose column isn't one of keep, for removing rows
+// that are no longer in the source. It returns how many rows were deleted.
+func (t *SyncTx) DeleteExcept(ctx context.Context, table, column string, keep []string) (int, error) {
+	if err := t.checkTable(table); err != nil {
+		return 0, err
+	}
+	result, err := t.tx.ExecContext(ctx, deleteExceptQuery(table, column), pq.Array(keep))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// deleteExceptQuery builds the query for DeleteExcept, the column is compared as text
+func deleteExceptQuery(table, column string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE NOT (%s::text = ANY($1));", pq.QuoteIdentifier(table), pq.QuoteIdentifier(column))
+}
+
+// Upsert writes values to the columns of a new row in table. If a row with the same conflict columns exists
+// its other columns are updated instead. The returning columns of the written row are scanned into dest.
+// It returns whether a new row was inserted.
+func (t *SyncTx) Upsert(ctx context.Context, table string, columns []string, values []interface{}, conflict []string, returning []string, dest ...interface{}) (bool, error) {
+	if err := t.checkTable(table); err != nil {
+		return false, err
+	}
+	if len(columns) != len(values) || len(returning) != len(dest) {
+		return false, fmt.Errorf("upsert into %s needs a value for every column and a dest for every returned column", table)
+	}
+
+	var inserted bool
+	err := t.tx.QueryRowContext(ctx, upsertQuery(table, columns, conflict, returning), values...).Scan(append(dest, &inserted)...)
+	return inserted, err
+}
+
+// upsertQuery builds the query for Upsert, the last column it returns is whether the row was inserted
+func upsertQuery(table string, columns, conflict, returning []string) string {
+	quote := func(names []string) []string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = pq.QuoteIdentifier(name)
+		}
+		return quoted
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES(%s)",
+		pq.QuoteIdentifier(table), strings.Join(quote(columns), ", "), strings.Join(placeholders, ","))
+
+	if len(conflict) > 0 {
+		var updates []string
+		for _, col := range columns {
+			if !containsString(conflict, col) {
+				updates = append(updates, fmt.Sprintf("%s=EXCLUDED.%[1]s", pq.QuoteIdentifier(col)))
+			}
+		}
+		// a no-op update so RETURNING still has the existing row
+		if len(updates) == 0 {
+			updates = []string{fmt.Sprintf("%s=EXCLUDED.%[1]s", pq.QuoteIdentifier(conflict[0]))}
+		}
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+			strings.Join(quote(conflict), ", "), strings.Join(updates, ", "))
+	}
+
+	// xmax is only 0 for rows that were inserted rather than updated
+	return query + fmt.Sprintf(" RETURNING %s;", strings.Join(append(quote(returning), "(xmax = 0)"), ", "))
+}
+
+// SyncInterval returns the shortest schedule of the sources, how often the data can change.
+// It's zero if every source is only synced on demand.
+func (db *DB) SyncInterval() time.Duration {
+	var interval time.Duration
+	for _, source := range db.sources {
+		if source.Schedule > 0 && (interval == 0 || source.Schedule < interval) {
+			interval = source.Schedule
+		}
+	}
+	return interval
+}
+
+// StartSchedules syncs every source with a schedule in the background until ctx is done
+func (db *DB) StartSchedules(ctx context.Context) {
+	log := logging.FromContext(ctx)
+
+	for _, source := range db.sources {
+		if source.Schedule <= 0 {
+			continue
+		}
+		log.Infof("Syncing %s every %s", source.Name, source.Schedule)
+		go db.runSchedule(ctx, source.Name, source.Schedule)
+	}
+}
+
+func (db *DB) runSchedule(ctx context.Context, source string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// failures are logged and recorded by the sync, the next tick tries again.
+			// It's skipped if a dependency's last run failed, until the dependency syncs again.
+			db.Sync(ctx, source)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func noopFetch(ctx context.Context) (interface{}, int, error) {
+	return nil, 0, nil
+}
+
+func noopWrite(ctx context.Context, tx *SyncTx, rows interface{}, run *models.SyncRun) ([]models.Event, error) {
+	return nil, nil
+}
+
+func Test_Registry_Register(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      Source
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			source: Source{Name: "players", Tables: []string{"player"}, Fetch: noopFetch, Write: noopWrite},
+		},
+		{
+			name:        "no name",
+			source:      Source{Fetch: noopFetch, Write: noopWrite},
+			expectedErr: "source must have a name",
+		},
+		{
+			name:        "no write func",
+			source:      Source{Name: "players", Fetch: noopFetch},
+			expectedErr: "source players must have fetch and write funcs",
+		},
+		{
+			name:        "duplicate",
+			source:      Source{Name: TeamStandingsSource, Fetch: noopFetch, Write: noopWrite},
+			expectedErr: "source team_standings is already registered",
+		},
+		{
+			name:        "unknown table",
+			source:      Source{Name: "matches", Tables: []string{"match"}, Fetch: noopFetch, Write: noopWrite},
+			expectedErr: "source matches writes to unknown table match",
+		},
+	}
+
+	for _, test := range tests {
+		registry := NewRegistry()
+		require.NoError(t, registry.Register(Source{Name: TeamStandingsSource, Fetch: noopFetch, Write: noopWrite}))
+
+		err := registry.Register(test.source)
+		if test.expectedErr == "" {
+			require.NoErrorf(t, err, "%q", test.name)
+		} else {
+			require.EqualErrorf(t, err, test.expectedErr, "%q", test.name)
+		}
+	}
+}
+
+func Test_Registry_Ordered(t *testing.T) {
+	tests := []struct {
+		name          string
+		sources       []Source
+		expectedOrder []string
+		expectedErr   string
+	}{
+		{
+			name:          "empty",
+			expectedOrder: []string{},
+		},
+		{
+			name: "registration order without dependencies",
+			sources: []Source{
+				{Name: "a"}, {Name: "b"}, {Name: "c"},
+			},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		{
+			name: "dependencies first",
+			sources: []Source{
+				{Name: "stats", DependsOn: []string{"players", "matches"}},
+				{Name: "players", DependsOn: []string{"teams"}},
+				{Name: "matches", DependsOn: []string{"teams"}},
+				{Name: "teams"},
+				{Name: "transactions", DependsOn: []string{"players"}},
+			},
+			expectedOrder: []string{"teams", "players", "matches", "stats", "transactions"},
+		},
+		{
+			name: "unknown dependency",
+			sources: []Source{
+				{Name: "players", DependsOn: []string{"teams"}},
+			},
+			expectedErr: "source players depends on unknown source teams",
+		},
+		{
+			name: "cycle",
+			sources: []Source{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+			expectedErr: "sources c and a depend on each other",
+		},
+	}
+
+	for _, test := range tests {
+		registry := NewRegistry()
+		for _, s := range test.sources {
+			s.Fetch, s.Write = noopFetch, noopWrite
+			require.NoError(t, registry.Register(s))
+		}
+
+		ordered, err := registry.Ordered()
+		if test.expectedErr != "" {
+			require.EqualErrorf(t, err, test.expectedErr, "%q", test.name)
+			continue
+		}
+		require.NoErrorf(t, err, "%q", test.name)
+		names := make([]string, len(ordered))
+		for i, s := range ordered {
+			names[i] = s.Name
+		}
+		require.Equalf(t, test.expectedOrder, names, "%q", test.name)
+	}
+}
+
+func Test_DB_Sources(t *testing.T) {
+	db := &DB{sources: []Source{
+		{Name: "teams", SpreadsheetID: "abc"},
+		{Name: "players", SpreadsheetID: "abc"},
+		{Name: "stats", SpreadsheetID: "def"},
+		{Name: "manual"},
+	}}
+
+	require.Equal(t, []string{"teams", "players", "stats", "manual"}, db.Sources())
+	require.Equal(t, []string{"abc", "def"}, db.DataSources())
+
+	require.Equal(t, []string{}, (&DB{}).Sources())
+	require.Equal(t, []string{}, (&DB{}).DataSources())
+}
+
+func Test_DB_SyncInterval(t *testing.T) {
+	db := &DB{sources: []Source{
+		{Name: "teams", Schedule: 10 * time.Minute},
+		{Name: "manual"},
+		{Name: "stats", Schedule: 5 * time.Minute},
+	}}
+	require.Equal(t, 5*time.Minute, db.SyncInterval())
+
+	require.Equal(t, time.Duration(0), (&DB{sources: []Source{{Name: "manual"}}}).SyncInterval())
+}
+
+func Test_failedDependency(t *testing.T) {
+	source := Source{Name: "stats", DependsOn: []string{"players", "matches"}}
+
+	require.Equal(t, "", failedDependency(source, nil))
+	require.Equal(t, "", failedDependency(source, []string{"teams"}))
+	require.Equal(t, "matches", failedDependency(source, []string{"teams", "matches"}))
+}
+
+func Test_SyncTx_checkTable(t *testing.T) {
+	tx := &SyncTx{source: Source{Name: "players", Tables: []string{"player"}}}
+
+	require.NoError(t, tx.checkTable("player"))
+	require.EqualError(t, tx.checkTable("team"), "source players can't write to team, it isn't one of its tables")
+	require.EqualError(t, tx.DeleteAll(context.Background(), "team"), "source players can't write to team, it isn't one of its tables")
+	_, err := tx.Upsert(context.Background(), "team", []string{"name"}, []interface{}{"A"}, nil, nil)
+	require.EqualError(t, err, "source players can't write to team, it isn't one of its tables")
+	_, err = tx.DeleteExcept(context.Background(), "team", "team_id", []string{"1"})
+	require.EqualError(t, err, "source players can't write to team, it isn't one of its tables")
+	_, err = tx.Upsert(context.Background(), "player", []string{"rsc_id", "name"}, []interface{}{"RSC001"}, nil, nil)
+	require.EqualError(t, err, "upsert into player needs a value for every column and a dest for every returned column")
+}
+
+func Test_deleteExceptQuery(t *testing.T) {
+	require.Equal(t, `DELETE FROM "team" WHERE NOT ("team_id"::text = ANY($1));`, deleteExceptQuery("team", "team_id"))
+}
+
+func Test_upsertQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		columns   []string
+		conflict  []string
+		returning []string
+		expected  string
+	}{
+		{
+			name:     "insert",
+			columns:  []string{"rsc_id", "name"},
+			expected: `INSERT INTO "player" ("rsc_id", "name") VALUES($1,$2) RETURNING (xmax = 0);`,
+		},
+		{
+			name:      "update other columns on conflict",
+			columns:   []string{"name", "franchise", "conference", "tier"},
+			conflict:  []string{"name", "franchise", "tier"},
+			returning: []string{"team_id"},
+			expected: `INSERT INTO "player" ("name", "franchise", "conference", "tier") VALUES($1,$2,$3,$4)` +
+				` ON CONFLICT ("name", "franchise", "tier") DO UPDATE SET "conference"=EXCLUDED."conference" RETURNING "team_id", (xmax = 0);`,
+		},
+		{
+			name:      "only conflict columns",
+			columns:   []string{"rsc_id"},
+			conflict:  []string{"rsc_id"},
+			returning: []string{"rsc_id"},
+			expected:  `INSERT INTO "player" ("rsc_id") VALUES($1) ON CONFLICT ("rsc_id") DO UPDATE SET "rsc_id"=EXCLUDED."rsc_id" RETURNING "rsc_id", (xmax = 0);`,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, upsertQuery("player", test.columns, test.conflict, test.returning), "%q", test.name)
+	}
+}
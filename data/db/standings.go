@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// querier is anything that can run a query, like *sql.DB or *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryStandings gets every team's standing matching conditionalStr, a WHERE clause on the team table
+func queryStandings(ctx context.Context, q querier, conditionalStr string, params []interface{}) ([]models.Standing, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`
+		SELECT team.team_id, team.name, team.franchise, team.conference, team.tier, team.division,
+			s.overall_wins, s.overall_losses, s.conference_wins, s.conference_losses, s.division_wins, s.division_losses,
+			s.series_wins, s.series_losses, s.win_percentage, s.game_differential, s.games_behind, COALESCE(s.streak, ''), s.seed
+		FROM team_standing s JOIN team ON team.team_id = s.team_id %s
+		ORDER BY team.team_id;
+	`, conditionalStr), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []models.Standing{}
+	for rows.Next() {
+		s := models.Standing{}
+		var divisionWins, divisionLosses *int
+		err := rows.Scan(
+			&s.Team.TeamID, &s.Team.Name, &s.Team.Franchise, &s.Team.Conference, &s.Team.Tier, &s.Team.Division,
+			&s.OverallRecord.Wins, &s.OverallRecord.Losses, &s.ConferenceRecord.Wins, &s.ConferenceRecord.Losses,
+			&divisionWins, &divisionLosses,
+			&s.SeriesRecord.Wins, &s.SeriesRecord.Losses, &s.WinPercentage, &s.GameDifferential, &s.GamesBehind, &s.Streak, &s.Seed,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if divisionWins != nil && divisionLosses != nil {
+			s.DivisionRecord = &models.Record{Wins: *divisionWins, Losses: *divisionLosses}
+		}
+		standings = append(standings, s)
+	}
+
+	return standings, rows.Err()
+}
+
+// teamIDsToInts converts team ids to ints for ANY($1) queries, returning ErrInvalidTypeForQuery if any aren't ints
+func teamIDsToInts(teamIDs []string) ([]int64, error) {
+	ids := make([]int64, len(teamIDs))
+	for i, id := range teamIDs {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidTypeForQuery
+		}
+		ids[i] = n
+	}
+	return ids, nil
+}
+
+// StandingsStore gets the standings of teams
+type StandingsStore interface {
+	GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error)
+	GetAllStandings(ctx context.Context, query GetAllTeamsQuery) ([]models.Standing, error)
+}
+
+// GetStandingsByTeamIDs returns the standings of all of the given teams in one query
+func (db *DB) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	defer metrics.ObserveDBQuery("GetStandingsByTeamIDs", time.Now())
+	log := logging.FromContext(ctx)
+
+	ids, err := teamIDsToInts(teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	standings, err := queryStandings(ctx, db.sqlDB, "WHERE team.team_id = ANY($1)", []interface{}{pq.Array(ids)})
+	if err != nil {
+		log.Errorf("Error getting standings from db: %v", err)
+		return nil, err
+	}
+	return standings, nil
+}
+
+// GetAllStandings returns the standings of every team matching the query
+func (db *DB) GetAllStandings(ctx context.Context, query GetAllTeamsQuery) ([]models.Standing, error) {
+	defer metrics.ObserveDBQuery("GetAllStandings", time.Now())
+	log := logging.FromContext(ctx)
+
+	conditionalStr, params, err := query.buildQueryStr(1)
+	if err != nil {
+		log.Warnf("Error making sql query from GetAllTeamsQuery %+v", query)
+		return nil, err
+	}
+	// the team filters are on bare column names, which would be ambiguous in the join with team_standing
+	if conditionalStr != "" {
+		conditionalStr = fmt.Sprintf("WHERE team.team_id IN (SELECT team_id FROM team %s)", conditionalStr)
+	}
+
+	standings, err := queryStandings(ctx, db.sqlDB, conditionalStr, params)
+	if err != nil {
+		log.Errorf("Error getting standings from db: %v", err)
+		return nil, err
+	}
+	return standings, nil
+}
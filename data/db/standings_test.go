@@ -0,0 +1,16 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_teamIDsToInts(t *testing.T) {
+	ids, err := teamIDsToInts([]string{"1", "20"})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 20}, ids)
+
+	_, err = teamIDsToInts([]string{"1", "abc"})
+	require.Equal(t, ErrInvalidTypeForQuery, err)
+}
@@ -0,0 +1,482 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/events"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/sheets"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// TeamStandingsSource is the name of the team standings data source
+const TeamStandingsSource = "team_standings"
+
+// ErrUnknownSource is returned if a sync is requested for a source that doesn't exist
+var ErrUnknownSource error = errors.New("Unknown data source")
+
+// Syncer pulls data sources into the db and keeps a record of each run
+type Syncer interface {
+	Sources() []string
+	Sync(ctx context.Context, source string) ([]models.SyncRun, error)
+	GetSyncRuns(ctx context.Context, limit int) ([]models.SyncRun, error)
+}
+
+// Sources returns the names of all of the data sources that can be synced, in the order they're synced
+func (db *DB) Sources() []string {
+	names := make([]string, len(db.sources))
+	for i, source := range db.sources {
+		names[i] = source.Name
+	}
+	return names
+}
+
+// NewTeamStandingsSource makes the source that syncs the teams and their standings from sheet
+func NewTeamStandingsSource(sheet sheets.TeamStandingsRetriever) Source {
+	return Source{
+		Name:          TeamStandingsSource,
+		SpreadsheetID: sheet.SpreadsheetID(),
+		SheetName:     sheet.SheetName(),
+		Tables:        []string{"team", "team_standing"},
+		Fetch: func(ctx context.Context) (interface{}, int, error) {
+			standings, err := sheet.GetTeamStandingsFromSheet()
+			return standings, len(standings), err
+		},
+		Write: func(ctx context.Context, tx *SyncTx, rows interface{}, run *models.SyncRun) ([]models.Event, error) {
+			return writeTeamStandings(ctx, tx, rows.([]models.Standing), run)
+		},
+	}
+}
+
+// syncListener is called when a sync commits to any of its tables, or any table if it has none
+type syncListener struct {
+	tables []string
+	f      func()
+}
+
+func (l syncListener) wants(tables []string) bool {
+	if len(l.tables) == 0 {
+		return true
+	}
+	for _, table := range tables {
+		if containsString(l.tables, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnSyncCommit registers f to be called every time a sync commits new data to the db
+func (db *DB) OnSyncCommit(f func()) {
+	db.OnTablesCommit(nil, f)
+}
+
+// OnTablesCommit registers f to be called every time a sync commits new data to any of tables,
+// like clearing a cache that only reads from them
+func (db *DB) OnTablesCommit(tables []string, f func()) {
+	db.syncMu.Lock()
+	defer db.syncMu.Unlock()
+	db.syncListeners = append(db.syncListeners, syncListener{tables: tables, f: f})
+}
+
+func (db *DB) notifySyncListeners(tables []string) {
+	db.syncMu.RLock()
+	defer db.syncMu.RUnlock()
+	for _, l := range db.syncListeners {
+		if l.wants(tables) {
+			l.f()
+		}
+	}
+}
+
+// OnEvents registers f to be called with the changes found every time a sync commits
+func (db *DB) OnEvents(f func([]models.Event)) {
+	db.syncMu.Lock()
+	defer db.syncMu.Unlock()
+	db.eventListeners = append(db.eventListeners, f)
+}
+
+func (db *DB) notifyEventListeners(events []models.Event) {
+	if len(events) == 0 {
+		return
+	}
+	db.syncMu.RLock()
+	defer db.syncMu.RUnlock()
+	for _, f := range db.eventListeners {
+		f(events)
+	}
+}
+
+// Sync pulls the given source into the db, or every source if source is empty.
+// A run is returned and recorded for every source synced. The error is the first failed run's error.
+// Sources are synced after their dependencies, a source isn't synced if one of its dependencies failed,
+// or when syncing just that source, if a dependency's last run failed.
+func (db *DB) Sync(ctx context.Context, source string) ([]models.SyncRun, error) {
+	sources := db.sources
+	if source != "" {
+		sources = nil
+		for _, s := range db.sources {
+			if s.Name == source {
+				sources = []Source{s}
+			}
+		}
+		if sources == nil {
+			return nil, ErrUnknownSource
+		}
+	}
+
+	db.syncRunMu.Lock()
+	defer db.syncRunMu.Unlock()
+
+	var firstErr error
+	var failed []string
+	if source != "" && len(sources[0].DependsOn) > 0 {
+		// the dependencies aren't being synced now, so go by how their last runs went
+		var err error
+		if failed, err = db.failedLastRuns(ctx, sources[0].DependsOn); err != nil {
+			return nil, err
+		}
+	}
+	runs := make([]models.SyncRun, 0, len(sources))
+	for _, s := range sources {
+		run, err := db.runSync(ctx, s, failedDependency(s, failed))
+		if err != nil {
+			failed = append(failed, s.Name)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	if firstErr == nil {
+		db.setLastSync(time.Now())
+	}
+
+	return runs, firstErr
+}
+
+// failedDependency returns the first dependency of source that failed to sync, empty if none did
+func failedDependency(source Source, failed []string) string {
+	for _, dep := range source.DependsOn {
+		if containsString(failed, dep) {
+			return dep
+		}
+	}
+	return ""
+}
+
+// runSync syncs source and records the run, if failedDep is set the run is recorded as failed without syncing
+func (db *DB) runSync(ctx context.Context, source Source, failedDep string) (models.SyncRun, error) {
+	log := logging.FromContext(ctx)
+
+	run := models.SyncRun{Source: source.Name, StartedAt: time.Now()}
+
+	var err error
+	var events []models.Event
+	if failedDep != "" {
+		err = fmt.Errorf("dependency %s failed to sync", failedDep)
+	} else {
+		events, err = db.syncSource(ctx, source, &run)
+	}
+	run.DurationMS = time.Since(run.StartedAt).Milliseconds()
+
+	if err != nil {
+		log.Errorf("Failed to sync %s: %v", source.Name, err)
+		errStr := err.Error()
+		run.Error = &errStr
+	} else if run.Skipped {
+		log.Infof("%s hasn't changed since the last sync, skipped writing it", source.Name)
+	} else {
+		db.setLastChange(time.Now())
+		db.notifySyncListeners(source.Tables)
+		db.notifyEventListeners(events)
+	}
+
+	if recordErr := db.recordSyncRun(ctx, &run); recordErr != nil {
+		log.Errorf("Failed to record sync run: %v", recordErr)
+	}
+
+	return run, err
+}
+
+// hashRows hashes the rows fetched from a source so unchanged data can be spotted
+func hashRows(rows interface{}) (string, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lastContentHash returns the content hash of the last successful run of a source, empty if there isn't one
+func (db *DB) lastContentHash(ctx context.Context, source string) (string, error) {
+	var hash sql.NullString
+	err := db.sqlDB.QueryRowContext(ctx, `
+		SELECT content_hash FROM sync_run WHERE source = $1 AND error IS NULL ORDER BY started_at DESC LIMIT 1;
+	`, source).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return hash.String, err
+}
+
+// skipIfUnchanged hashes the fetched rows into the run and marks it skipped if they match the last successful run
+func (db *DB) skipIfUnchanged(ctx context.Context, run *models.SyncRun, rows interface{}) error {
+	hash, err := hashRows(rows)
+	if err != nil {
+		return err
+	}
+	run.ContentHash = hash
+
+	lastHash, err := db.lastContentHash(ctx, run.Source)
+	if err != nil {
+		return err
+	}
+	if lastHash == hash {
+		run.Skipped = true
+		run.Unchanged = run.RowsFetched
+	}
+	return nil
+}
+
+// failedLastRuns returns which of sources failed their last sync run
+func (db *DB) failedLastRuns(ctx context.Context, sources []string) ([]string, error) {
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT source FROM (
+			SELECT DISTINCT ON (source) source, error FROM sync_run WHERE source = ANY($1) ORDER BY source, started_at DESC
+		) AS last_run WHERE error IS NOT NULL;
+	`, pq.Array(sources))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failed []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, err
+		}
+		failed = append(failed, source)
+	}
+	return failed, rows.Err()
+}
+
+// syncSource fetches the rows of a source and writes them, with the changes found, in one transaction.
+// Nothing is written if the rows are the same as the last successful sync.
+func (db *DB) syncSource(ctx context.Context, source Source, run *models.SyncRun) ([]models.Event, error) {
+	log := logging.FromContext(ctx)
+
+	rows, count, err := source.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	run.RowsFetched = count
+
+	if err := db.skipIfUnchanged(ctx, run, rows); err != nil {
+		log.Errorf("Failed to check if %s changed: %v", run.Source, err)
+		return nil, err
+	}
+	if run.Skipped {
+		return nil, nil
+	}
+
+	tx, err := db.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := source.Write(ctx, &SyncTx{tx: tx, source: source}, rows, run)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = insertEvents(ctx, tx, changes); err != nil {
+		log.Errorf("Failed to insert events into event table: %v", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	sheetName := source.SheetName
+	if sheetName == "" {
+		sheetName = source.Name
+	}
+	metrics.AddRowsIngested(sheetName, count)
+	return changes, nil
+}
+
+// writeTeamStandings upserts every team in the standings sheet, replaces the stored standings and deletes
+// the teams that are no longer in the sheet, returning the changes between the old and new standings
+func writeTeamStandings(ctx context.Context, tx *SyncTx, teamData []models.Standing, run *models.SyncRun) ([]models.Event, error) {
+	log := logging.FromContext(ctx)
+
+	previous, err := queryStandings(ctx, tx, "", nil)
+	if err != nil {
+		log.Errorf("Failed to get previous standings: %v", err)
+		return nil, err
+	}
+	if len(teamData) == 0 && len(previous) > 0 {
+		// more likely a broken sheet than every team leaving the league
+		return nil, fmt.Errorf("the standings sheet has no teams, not removing all %d of them", len(previous))
+	}
+
+	current := make([]models.Standing, 0, len(teamData))
+	for _, t := range teamData {
+		_, err := tx.Upsert(ctx, "team",
+			[]string{"name", "franchise", "conference", "tier", "division"},
+			[]interface{}{t.Team.Name, t.Team.Franchise, t.Team.Conference, t.Team.Tier, t.Team.Division},
+			[]string{"name", "franchise", "tier"},
+			[]string{"team_id"}, &t.Team.TeamID,
+		)
+		if err != nil {
+			log.Errorf("Failed to insert team into team table: %v", err)
+			return nil, err
+		}
+		current = append(current, t)
+	}
+
+	if err = tx.DeleteAll(ctx, "team_standing"); err != nil {
+		log.Errorf("Failed to clear team_standing table: %v", err)
+		return nil, err
+	}
+	for _, s := range current {
+		var divisionWins, divisionLosses *int
+		if s.DivisionRecord != nil {
+			divisionWins, divisionLosses = &s.DivisionRecord.Wins, &s.DivisionRecord.Losses
+		}
+		var streak *string
+		if s.Streak != "" {
+			streak = &s.Streak
+		}
+		_, err := tx.Upsert(ctx, "team_standing",
+			[]string{
+				"team_id", "overall_wins", "overall_losses", "conference_wins", "conference_losses", "division_wins", "division_losses",
+				"series_wins", "series_losses", "win_percentage", "game_differential", "games_behind", "streak", "seed",
+			},
+			[]interface{}{
+				s.Team.TeamID, s.OverallRecord.Wins, s.OverallRecord.Losses, s.ConferenceRecord.Wins, s.ConferenceRecord.Losses, divisionWins, divisionLosses,
+				s.SeriesRecord.Wins, s.SeriesRecord.Losses, s.WinPercentage, s.GameDifferential, s.GamesBehind, streak, s.Seed,
+			},
+			[]string{"team_id"}, nil,
+		)
+		if err != nil {
+			log.Errorf("Failed to insert standing into team_standing table: %v", err)
+			return nil, err
+		}
+	}
+
+	teamIDs := make([]string, len(current))
+	for i, s := range current {
+		teamIDs[i] = s.Team.TeamID
+	}
+	if _, err := tx.DeleteExcept(ctx, "team", "team_id", teamIDs); err != nil {
+		log.Errorf("Failed to delete removed teams from team table: %v", err)
+		return nil, err
+	}
+
+	changes := events.Diff(previous, current, time.Now())
+	countChanges(run, current, changes)
+	return changes, nil
+}
+
+// countChanges sets how many of the current rows were added, changed or unchanged, and how many were removed,
+// from the changes found between the old and new standings
+func countChanges(run *models.SyncRun, current []models.Standing, changes []models.Event) {
+	changed := map[string]bool{}
+	for _, e := range changes {
+		switch e.Type {
+		case models.EventTeamAdded:
+			run.Added++
+		case models.EventTeamRemoved:
+			run.Removed++
+		default:
+			// a team can change in more than one way, it's only counted once
+			changed[e.Current.Team.TeamID] = true
+		}
+	}
+	run.Changed = len(changed)
+	run.Unchanged = len(current) - run.Added - run.Changed
+}
+
+func (db *DB) recordSyncRun(ctx context.Context, run *models.SyncRun) error {
+	return db.sqlDB.QueryRowContext(ctx, `
+		INSERT INTO sync_run (source, started_at, duration_ms, rows_fetched, added, changed, removed, unchanged, content_hash, skipped, error)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,NULLIF($9, ''),$10,$11) RETURNING sync_run_id;
+	`, run.Source, run.StartedAt, run.DurationMS, run.RowsFetched, run.Added, run.Changed, run.Removed, run.Unchanged, run.ContentHash, run.Skipped, run.Error).Scan(&run.ID)
+}
+
+// GetSyncRuns returns the most recent sync runs, newest first
+func (db *DB) GetSyncRuns(ctx context.Context, limit int) ([]models.SyncRun, error) {
+	defer metrics.ObserveDBQuery("GetSyncRuns", time.Now())
+	log := logging.FromContext(ctx)
+
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT sync_run_id, source, started_at, duration_ms, rows_fetched, added, changed, removed, unchanged, COALESCE(content_hash, ''), skipped, error
+		FROM sync_run ORDER BY started_at DESC LIMIT $1;
+	`, limit)
+	if err != nil {
+		log.Errorf("Error getting sync runs from db: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.SyncRun{}
+	for rows.Next() {
+		run := models.SyncRun{}
+		err := rows.Scan(&run.ID, &run.Source, &run.StartedAt, &run.DurationMS, &run.RowsFetched, &run.Added, &run.Changed, &run.Removed, &run.Unchanged, &run.ContentHash, &run.Skipped, &run.Error)
+		if err != nil {
+			log.Errorf("Error scanning a sync run: %s", err)
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// loadLastSync sets LastSync and LastChange from the successful runs recorded in the db,
+// so the age of the data is still known if the API starts without being able to sync
+func (db *DB) loadLastSync() error {
+	var lastSync, lastChange sql.NullTime
+	err := db.sqlDB.QueryRow(`
+		SELECT
+			MAX(started_at + duration_ms * INTERVAL '1 millisecond'),
+			MAX(started_at + duration_ms * INTERVAL '1 millisecond') FILTER (WHERE NOT skipped)
+		FROM sync_run WHERE error IS NULL;
+	`).Scan(&lastSync, &lastChange)
+	if err != nil {
+		return err
+	}
+	if lastSync.Valid {
+		db.setLastSync(lastSync.Time)
+	}
+	if lastChange.Valid {
+		db.setLastChange(lastChange.Time)
+	}
+	return nil
+}
+
+func containsString(vals []string, s string) bool {
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
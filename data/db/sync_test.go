@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_containsString(t *testing.T) {
+	require.Equal(t, true, containsString([]string{"a", "b"}, "b"))
+	require.Equal(t, false, containsString([]string{"a", "b"}, "c"))
+	require.Equal(t, false, containsString(nil, "a"))
+}
+
+func Test_Sync_UnknownSource(t *testing.T) {
+	_, err := (&DB{}).Sync(context.Background(), "abc")
+	require.Equal(t, ErrUnknownSource, err)
+}
+
+func Test_OnSyncCommit(t *testing.T) {
+	db := &DB{}
+	calls := 0
+	db.OnSyncCommit(func() { calls++ })
+	db.OnSyncCommit(func() { calls++ })
+
+	db.notifySyncListeners([]string{"team"})
+
+	require.Equal(t, 2, calls)
+}
+
+func Test_OnTablesCommit(t *testing.T) {
+	db := &DB{}
+	var teamCalls, playerCalls, allCalls int
+	db.OnTablesCommit([]string{"team", "team_standing"}, func() { teamCalls++ })
+	db.OnTablesCommit([]string{"player"}, func() { playerCalls++ })
+	db.OnSyncCommit(func() { allCalls++ })
+
+	db.notifySyncListeners([]string{"team_standing"})
+	db.notifySyncListeners([]string{"player", "team"})
+
+	require.Equal(t, 2, teamCalls)
+	require.Equal(t, 1, playerCalls)
+	require.Equal(t, 2, allCalls)
+}
+
+func Test_hashRows(t *testing.T) {
+	division := "Blue"
+	standings := []models.Standing{
+		{Team: models.Team{Name: "Rockets", Division: &division}, OverallRecord: models.Record{Wins: 3}},
+		{Team: models.Team{Name: "Comets"}},
+	}
+
+	hash, err := hashRows(standings)
+	require.NoError(t, err)
+	require.Len(t, hash, 64)
+
+	same, err := hashRows([]models.Standing{standings[0], standings[1]})
+	require.NoError(t, err)
+	require.Equal(t, hash, same)
+
+	changed := []models.Standing{standings[0], standings[1]}
+	changed[1].OverallRecord.Losses = 1
+	different, err := hashRows(changed)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, different)
+}
+
+func Test_DB_LastChange(t *testing.T) {
+	db := &DB{}
+	changed := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	db.setLastChange(changed)
+
+	// a sync that skips writing moves LastSync but not LastChange
+	db.setLastSync(changed.Add(time.Hour))
+	require.Equal(t, changed, db.LastChange())
+	require.Equal(t, changed.Add(time.Hour), db.LastSync())
+}
+
+func Test_countChanges(t *testing.T) {
+	team := func(id string) *models.Standing {
+		return &models.Standing{Team: models.Team{TeamID: id}}
+	}
+	current := []models.Standing{*team("1"), *team("2"), *team("3"), *team("4")}
+	changes := []models.Event{
+		{Type: models.EventTeamAdded, Current: team("1")},
+		{Type: models.EventStandingRecordChanged, Previous: team("2"), Current: team("2")},
+		{Type: models.EventStandingStatsChanged, Previous: team("2"), Current: team("2")},
+		{Type: models.EventTeamConferenceChanged, Previous: team("3"), Current: team("3")},
+		{Type: models.EventTeamRemoved, Previous: team("5")},
+	}
+
+	run := models.SyncRun{RowsFetched: len(current)}
+	countChanges(&run, current, changes)
+
+	require.Equal(t, 1, run.Added)
+	require.Equal(t, 2, run.Changed)
+	require.Equal(t, 1, run.Removed)
+	require.Equal(t, 1, run.Unchanged)
+}
@@ -1,13 +1,16 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
-	log "github.com/sirupsen/logrus"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
 )
 
 // ErrInvalidTypeForQuery is returned if a given query is the wrong type
@@ -118,7 +121,10 @@ func (q GetAllTeamsQuery) buildQueryStr(startingNum int) (string, []interface{},
 	return queryStr, stringSliceToInterfaceSlice(params), nil
 }
 
-func (db *DB) GetAllTeams(query GetAllTeamsQuery) ([]models.Team, error) {
+func (db *DB) GetAllTeams(ctx context.Context, query GetAllTeamsQuery) ([]models.Team, error) {
+	defer metrics.ObserveDBQuery("GetAllTeams", time.Now())
+	log := logging.FromContext(ctx)
+
 	conditionalStr, params, err := query.buildQueryStr(1)
 	if err != nil {
 		log.Warnf("Error making sql query from GetAllTeamsQuery %+v", query)
@@ -129,7 +135,7 @@ func (db *DB) GetAllTeams(query GetAllTeamsQuery) ([]models.Team, error) {
 		SELECT team_id, name, franchise, conference, tier, division FROM team %s;
 	`, conditionalStr)
 
-	rows, err := db.sqlDB.Query(sqlQuery, params...)
+	rows, err := db.sqlDB.QueryContext(ctx, sqlQuery, params...)
 	if err != nil {
 		log.Errorf("Error getting all teams from db: %v", err)
 		return nil, err
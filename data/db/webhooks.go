@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+)
+
+// ErrWebhookNotFound is returned if a given webhook subscription doesn't exist
+var ErrWebhookNotFound error = errors.New("Webhook subscription not found")
+
+// WebhookStore holds webhook subscriptions and the log of deliveries to them
+type WebhookStore interface {
+	CreateWebhookSubscription(ctx context.Context, url string, eventTypes []models.EventType) (models.WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int) error
+	RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID, limit int) ([]models.WebhookDelivery, error)
+}
+
+func eventTypesToStrings(eventTypes []models.EventType) []string {
+	strs := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		strs[i] = string(t)
+	}
+	return strs
+}
+
+func stringsToEventTypes(strs []string) []models.EventType {
+	eventTypes := make([]models.EventType, len(strs))
+	for i, s := range strs {
+		eventTypes[i] = models.EventType(s)
+	}
+	return eventTypes
+}
+
+// CreateWebhookSubscription subscribes url to events, generating a secret to sign deliveries with
+func (db *DB) CreateWebhookSubscription(ctx context.Context, url string, eventTypes []models.EventType) (models.WebhookSubscription, error) {
+	defer metrics.ObserveDBQuery("CreateWebhookSubscription", time.Now())
+	log := logging.FromContext(ctx)
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	sub := models.WebhookSubscription{
+		URL:        url,
+		Secret:     hex.EncodeToString(secretBytes),
+		EventTypes: eventTypes,
+	}
+	err := db.sqlDB.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscription (url, secret, event_types)
+		VALUES($1,$2,$3) RETURNING webhook_subscription_id, created_at;
+	`, sub.URL, sub.Secret, pq.Array(eventTypesToStrings(eventTypes))).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		log.Errorf("Error inserting webhook subscription into db: %v", err)
+		return sub, err
+	}
+
+	return sub, nil
+}
+
+// GetWebhookSubscriptions returns every subscription, including their secrets
+func (db *DB) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	defer metrics.ObserveDBQuery("GetWebhookSubscriptions", time.Now())
+	log := logging.FromContext(ctx)
+
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT webhook_subscription_id, url, secret, event_types, created_at
+		FROM webhook_subscription ORDER BY webhook_subscription_id;
+	`)
+	if err != nil {
+		log.Errorf("Error getting webhook subscriptions from db: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub := models.WebhookSubscription{}
+		var eventTypes []string
+		err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, pq.Array(&eventTypes), &sub.CreatedAt)
+		if err != nil {
+			log.Errorf("Error scanning a webhook subscription: %s", err)
+			return nil, err
+		}
+		sub.EventTypes = stringsToEventTypes(eventTypes)
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// DeleteWebhookSubscription unsubscribes, returning ErrWebhookNotFound if the subscription doesn't exist
+func (db *DB) DeleteWebhookSubscription(ctx context.Context, id int) error {
+	defer metrics.ObserveDBQuery("DeleteWebhookSubscription", time.Now())
+	log := logging.FromContext(ctx)
+
+	result, err := db.sqlDB.ExecContext(ctx, `
+		DELETE FROM webhook_subscription WHERE webhook_subscription_id=$1;
+	`, id)
+	if err != nil {
+		log.Errorf("Error deleting webhook subscription: %v", err)
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// RecordWebhookDelivery adds a delivery attempt to the delivery log
+func (db *DB) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	defer metrics.ObserveDBQuery("RecordWebhookDelivery", time.Now())
+
+	_, err := db.sqlDB.ExecContext(ctx, `
+		INSERT INTO webhook_delivery (webhook_subscription_id, event_type, attempt, status_code, error, success, delivered_at)
+		VALUES($1,$2,$3,$4,$5,$6,$7);
+	`, delivery.SubscriptionID, delivery.EventType, delivery.Attempt, delivery.StatusCode, delivery.Error, delivery.Success, delivery.DeliveredAt)
+	return err
+}
+
+// GetWebhookDeliveries returns the most recent delivery attempts to a subscription, newest first
+func (db *DB) GetWebhookDeliveries(ctx context.Context, subscriptionID, limit int) ([]models.WebhookDelivery, error) {
+	defer metrics.ObserveDBQuery("GetWebhookDeliveries", time.Now())
+	log := logging.FromContext(ctx)
+
+	rows, err := db.sqlDB.QueryContext(ctx, `
+		SELECT webhook_delivery_id, webhook_subscription_id, event_type, attempt, status_code, error, success, delivered_at
+		FROM webhook_delivery WHERE webhook_subscription_id=$1
+		ORDER BY delivered_at DESC LIMIT $2;
+	`, subscriptionID, limit)
+	if err != nil {
+		log.Errorf("Error getting webhook deliveries from db: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d := models.WebhookDelivery{}
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.Attempt, &d.StatusCode, &d.Error, &d.Success, &d.DeliveredAt)
+		if err != nil {
+			log.Errorf("Error scanning a webhook delivery: %s", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
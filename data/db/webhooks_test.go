@@ -0,0 +1,16 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_eventTypesToStrings(t *testing.T) {
+	eventTypes := []models.EventType{models.EventTeamAdded, models.EventTeamRemoved}
+	strs := eventTypesToStrings(eventTypes)
+
+	require.Equal(t, []string{"team.added", "team.removed"}, strs)
+	require.Equal(t, eventTypes, stringsToEventTypes(strs))
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// subscriberBuffer is how many batches of events can wait for a slow subscriber
+const subscriberBuffer = 16
+
+// Broker fans out events to every subscriber.
+// Subscribers that fall too far behind are dropped, their channel is closed so they can resume from the event log.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan []models.Event]struct{}
+}
+
+// NewBroker makes an empty Broker
+func NewBroker() *Broker {
+	return &Broker{subscribers: map[chan []models.Event]struct{}{}}
+}
+
+// Subscribe returns a channel of published events and a func to unsubscribe
+func (b *Broker) Subscribe() (<-chan []models.Event, func()) {
+	ch := make(chan []models.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() { b.remove(ch) }
+}
+
+func (b *Broker) remove(ch chan []models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends events to every subscriber without blocking
+func (b *Broker) Publish(events []models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- events:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
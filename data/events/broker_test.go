@@ -0,0 +1,45 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Broker(t *testing.T) {
+	b := NewBroker()
+	a, unsubA := b.Subscribe()
+	c, unsubC := b.Subscribe()
+	t.Cleanup(unsubC)
+
+	events := []models.Event{{ID: 1, Type: models.EventTeamAdded}}
+	b.Publish(events)
+	require.Equal(t, events, <-a)
+	require.Equal(t, events, <-c)
+
+	unsubA()
+	_, open := <-a
+	require.Equal(t, false, open)
+	// unsubscribing twice is fine
+	unsubA()
+
+	b.Publish(events)
+	require.Equal(t, events, <-c)
+}
+
+func Test_Broker_DropsSlowSubscribers(t *testing.T) {
+	b := NewBroker()
+	ch, unsub := b.Subscribe()
+	t.Cleanup(unsub)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish([]models.Event{{ID: int64(i)}})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	require.Equal(t, subscriberBuffer, received)
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+func standingsByTeamID(standings []models.Standing) map[string]models.Standing {
+	byID := make(map[string]models.Standing, len(standings))
+	for _, s := range standings {
+		byID[s.Team.TeamID] = s
+	}
+	return byID
+}
+
+func sameConference(a, b models.Team) bool {
+	if a.Conference != b.Conference {
+		return false
+	}
+	if a.Division == nil || b.Division == nil {
+		return a.Division == b.Division
+	}
+	return *a.Division == *b.Division
+}
+
+// Diff compares the standings before and after a sync, matching them up by team ID,
+// and returns an event for every change. Events are ordered by team ID.
+func Diff(previous, current []models.Standing, now time.Time) []models.Event {
+	prevByID := standingsByTeamID(previous)
+	currByID := standingsByTeamID(current)
+
+	ids := make([]string, 0, len(prevByID)+len(currByID))
+	for id := range prevByID {
+		ids = append(ids, id)
+	}
+	for id := range currByID {
+		if _, ok := prevByID[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, aErr := strconv.Atoi(ids[i])
+		b, bErr := strconv.Atoi(ids[j])
+		if aErr != nil || bErr != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+
+	events := []models.Event{}
+	for _, id := range ids {
+		prev, hadPrev := prevByID[id]
+		curr, hasCurr := currByID[id]
+		prevCopy, currCopy := prev, curr
+
+		switch {
+		case !hadPrev:
+			events = append(events, models.Event{Type: models.EventTeamAdded, Current: &currCopy, CreatedAt: now})
+		case !hasCurr:
+			events = append(events, models.Event{Type: models.EventTeamRemoved, Previous: &prevCopy, CreatedAt: now})
+		default:
+			if !sameConference(prev.Team, curr.Team) {
+				events = append(events, models.Event{Type: models.EventTeamConferenceChanged, Previous: &prevCopy, Current: &currCopy, CreatedAt: now})
+			}
+			if !prev.SameRecords(curr) {
+				events = append(events, models.Event{Type: models.EventStandingRecordChanged, Previous: &prevCopy, Current: &currCopy, CreatedAt: now})
+			}
+			if !prev.SameStats(curr) {
+				events = append(events, models.Event{Type: models.EventStandingStatsChanged, Previous: &prevCopy, Current: &currCopy, CreatedAt: now})
+			}
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func strPointer(s string) *string {
+	return &s
+}
+
+func Test_Diff(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	unchanged := models.Standing{Team: models.Team{TeamID: "1", Conference: "Solar"}, OverallRecord: models.Record{Wins: 1}}
+	removed := models.Standing{Team: models.Team{TeamID: "2", Conference: "Solar"}}
+	added := models.Standing{Team: models.Team{TeamID: "10", Conference: "Lunar"}}
+	movedBefore := models.Standing{Team: models.Team{TeamID: "3", Conference: "Solar", Division: strPointer("A")}}
+	movedAfter := models.Standing{Team: models.Team{TeamID: "3", Conference: "Solar", Division: strPointer("B")}, OverallRecord: models.Record{Losses: 1}}
+	playedBefore := models.Standing{Team: models.Team{TeamID: "4", Conference: "Lunar"}}
+	playedAfter := models.Standing{Team: models.Team{TeamID: "4", Conference: "Lunar"}, OverallRecord: models.Record{Wins: 1}, Streak: "W1"}
+	seed := 8
+	overtakenBefore := models.Standing{Team: models.Team{TeamID: "5", Conference: "Lunar"}, Seed: &seed}
+	overtakenAfter := models.Standing{Team: models.Team{TeamID: "5", Conference: "Lunar"}, GamesBehind: 1}
+
+	events := Diff(
+		[]models.Standing{unchanged, removed, movedBefore, playedBefore, overtakenBefore},
+		[]models.Standing{added, playedAfter, unchanged, movedAfter, overtakenAfter},
+		now,
+	)
+
+	require.Equal(t, []models.Event{
+		{Type: models.EventTeamRemoved, Previous: &removed, CreatedAt: now},
+		{Type: models.EventTeamConferenceChanged, Previous: &movedBefore, Current: &movedAfter, CreatedAt: now},
+		{Type: models.EventStandingRecordChanged, Previous: &movedBefore, Current: &movedAfter, CreatedAt: now},
+		{Type: models.EventStandingRecordChanged, Previous: &playedBefore, Current: &playedAfter, CreatedAt: now},
+		{Type: models.EventStandingStatsChanged, Previous: &playedBefore, Current: &playedAfter, CreatedAt: now},
+		{Type: models.EventStandingStatsChanged, Previous: &overtakenBefore, Current: &overtakenAfter, CreatedAt: now},
+		{Type: models.EventTeamAdded, Current: &added, CreatedAt: now},
+	}, events)
+}
+
+func Test_Diff_NoChanges(t *testing.T) {
+	standings := []models.Standing{{Team: models.Team{TeamID: "1"}}}
+
+	require.Equal(t, []models.Event{}, Diff(standings, standings, time.Now()))
+}
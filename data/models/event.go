@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// EventType is the kind of change an Event describes
+type EventType string
+
+const (
+	// EventTeamAdded is sent when a team shows up in the standings
+	EventTeamAdded EventType = "team.added"
+	// EventTeamRemoved is sent when a team is no longer in the standings, it's deleted along with its standing
+	EventTeamRemoved EventType = "team.removed"
+	// EventTeamConferenceChanged is sent when a team moves conference or division
+	EventTeamConferenceChanged EventType = "team.conference_changed"
+	// EventStandingRecordChanged is sent when any of a team's records change
+	EventStandingRecordChanged EventType = "standing.record_changed"
+	// EventStandingStatsChanged is sent when a team's seed, games behind, win percentage, game differential or streak change.
+	// Most of them move with the records so it usually comes with a standing.record_changed, but seed and games behind
+	// also change when other teams play.
+	EventStandingStatsChanged EventType = "standing.stats_changed"
+)
+
+// EventTypes is every type of event that can be sent. There are no player events yet,
+// nothing syncs players so there are no roster changes to find.
+var EventTypes = []EventType{
+	EventTeamAdded,
+	EventTeamRemoved,
+	EventTeamConferenceChanged,
+	EventStandingRecordChanged,
+	EventStandingStatsChanged,
+}
+
+// Event describes a change in the data found by a sync
+type Event struct {
+	ID        int64     `json:"id"`
+	Type      EventType `json:"type"`
+	Previous  *Standing `json:"previous,omitempty"`
+	Current   *Standing `json:"current,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team returns the team the event is about
+func (e Event) Team() Team {
+	if e.Current != nil {
+		return e.Current.Team
+	}
+	if e.Previous != nil {
+		return e.Previous.Team
+	}
+	return Team{}
+}
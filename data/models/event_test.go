@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Event_Team(t *testing.T) {
+	previous := &Standing{Team: Team{TeamID: "1", Conference: "Solar"}}
+	current := &Standing{Team: Team{TeamID: "1", Conference: "Lunar"}}
+
+	require.Equal(t, "Lunar", Event{Previous: previous, Current: current}.Team().Conference)
+	require.Equal(t, "Solar", Event{Previous: previous}.Team().Conference)
+	require.Equal(t, Team{}, Event{}.Team())
+}
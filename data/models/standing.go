@@ -0,0 +1,49 @@
+package models
+
+// Standing holds standing stats about a current Team
+type Standing struct {
+	Team             Team    `json:"team"`
+	OverallRecord    Record  `json:"overall_record"`
+	ConferenceRecord Record  `json:"conference_record"`
+	DivisionRecord   *Record `json:"division_record,omitempty"`
+	// SeriesRecord is the team's record in best-of series, rather than games
+	SeriesRecord  Record  `json:"series_record"`
+	WinPercentage float64 `json:"win_percentage"`
+	// GameDifferential is games won minus games lost
+	GameDifferential int `json:"game_differential"`
+	// GamesBehind is how many games the team is behind the leader of its conference
+	GamesBehind float64 `json:"games_behind"`
+	// Streak is the team's current run of wins or losses, like "W3"
+	Streak string `json:"streak,omitempty"`
+	// Seed is the team's playoff seed, nil if it isn't in a playoff spot
+	Seed *int `json:"seed,omitempty"`
+}
+
+// Record holds Wins and Losses
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+// SameRecords returns whether both standings have the same overall, conference, series and division records
+func (s Standing) SameRecords(other Standing) bool {
+	if s.OverallRecord != other.OverallRecord || s.ConferenceRecord != other.ConferenceRecord || s.SeriesRecord != other.SeriesRecord {
+		return false
+	}
+	if s.DivisionRecord == nil || other.DivisionRecord == nil {
+		return s.DivisionRecord == other.DivisionRecord
+	}
+	return *s.DivisionRecord == *other.DivisionRecord
+}
+
+// SameStats returns whether both standings have the same seed, games behind, win percentage, game differential and streak
+func (s Standing) SameStats(other Standing) bool {
+	if s.GamesBehind != other.GamesBehind || s.WinPercentage != other.WinPercentage ||
+		s.GameDifferential != other.GameDifferential || s.Streak != other.Streak {
+		return false
+	}
+	if s.Seed == nil || other.Seed == nil {
+		return s.Seed == other.Seed
+	}
+	return *s.Seed == *other.Seed
+}
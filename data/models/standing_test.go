@@ -0,0 +1,129 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Standing_SameRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        Standing
+		b        Standing
+		expected bool
+	}{
+		{
+			name:     "Same",
+			a:        Standing{OverallRecord: Record{Wins: 1}, DivisionRecord: &Record{Wins: 1}},
+			b:        Standing{OverallRecord: Record{Wins: 1}, DivisionRecord: &Record{Wins: 1}},
+			expected: true,
+		},
+		{
+			name:     "Different overall",
+			a:        Standing{OverallRecord: Record{Wins: 1}},
+			b:        Standing{OverallRecord: Record{Wins: 2}},
+			expected: false,
+		},
+		{
+			name:     "Different conference",
+			a:        Standing{ConferenceRecord: Record{Losses: 1}},
+			b:        Standing{},
+			expected: false,
+		},
+		{
+			name:     "Different series",
+			a:        Standing{SeriesRecord: Record{Wins: 1}},
+			b:        Standing{},
+			expected: false,
+		},
+		{
+			name:     "Different division",
+			a:        Standing{DivisionRecord: &Record{Wins: 1}},
+			b:        Standing{DivisionRecord: &Record{Wins: 2}},
+			expected: false,
+		},
+		{
+			name:     "One without division",
+			a:        Standing{DivisionRecord: &Record{}},
+			b:        Standing{},
+			expected: false,
+		},
+		{
+			name:     "Both without division",
+			a:        Standing{},
+			b:        Standing{},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, test.a.SameRecords(test.b), "test %q failed", test.name)
+	}
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func Test_Standing_SameStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        Standing
+		b        Standing
+		expected bool
+	}{
+		{
+			name:     "Same",
+			a:        Standing{GamesBehind: 1.5, WinPercentage: 0.5, GameDifferential: 2, Streak: "W1", Seed: intPointer(3)},
+			b:        Standing{GamesBehind: 1.5, WinPercentage: 0.5, GameDifferential: 2, Streak: "W1", Seed: intPointer(3)},
+			expected: true,
+		},
+		{
+			name:     "Records are ignored",
+			a:        Standing{OverallRecord: Record{Wins: 1}},
+			b:        Standing{},
+			expected: true,
+		},
+		{
+			name:     "Different games behind",
+			a:        Standing{GamesBehind: 1},
+			b:        Standing{GamesBehind: 1.5},
+			expected: false,
+		},
+		{
+			name:     "Different win percentage",
+			a:        Standing{WinPercentage: 0.5},
+			b:        Standing{WinPercentage: 0.6},
+			expected: false,
+		},
+		{
+			name:     "Different game differential",
+			a:        Standing{GameDifferential: -1},
+			b:        Standing{},
+			expected: false,
+		},
+		{
+			name:     "Different streak",
+			a:        Standing{Streak: "W2"},
+			b:        Standing{Streak: "L1"},
+			expected: false,
+		},
+		{
+			name:     "Different seed",
+			a:        Standing{Seed: intPointer(1)},
+			b:        Standing{Seed: intPointer(2)},
+			expected: false,
+		},
+		{
+			name:     "Dropped out of the playoffs",
+			a:        Standing{Seed: intPointer(8)},
+			b:        Standing{},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, test.a.SameStats(test.b), "test %q failed", test.name)
+	}
+}
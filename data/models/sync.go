@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SyncRun describes one pull of a data source into the db
+type SyncRun struct {
+	ID         int       `json:"id"`
+	Source     string    `json:"source"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	// RowsFetched is the number of valid rows pulled from the source
+	RowsFetched int `json:"rows_fetched"`
+	// Added is the number of rows that weren't already in the db
+	Added int `json:"added"`
+	// Changed is the number of rows that were already in the db with different values
+	Changed int `json:"changed"`
+	// Removed is the number of rows deleted from the db because they're no longer in the source
+	Removed int `json:"removed"`
+	// Unchanged is the number of rows that were already in the db with the same values
+	Unchanged int `json:"unchanged"`
+	// ContentHash is a hash of the rows fetched, used to tell if the source changed since the last run
+	ContentHash string `json:"content_hash,omitempty"`
+	// Skipped is set if the rows fetched were the same as the last successful run's, so nothing was written
+	Skipped bool    `json:"skipped,omitempty"`
+	Error   *string `json:"error,omitempty"`
+}
+
+// Failed returns whether the run errored
+func (s SyncRun) Failed() bool {
+	return s.Error != nil
+}
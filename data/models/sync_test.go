@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SyncRun_Failed(t *testing.T) {
+	err := "some error"
+
+	require.Equal(t, false, SyncRun{}.Failed())
+	require.Equal(t, true, SyncRun{Error: &err}.Failed())
+}
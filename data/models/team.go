@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Team describes a team
 type Team struct {
@@ -18,17 +21,32 @@ func (t Team) String() string {
 
 // Player holds data about a player
 type Player struct {
-	RSCID  string
-	Name   string
-	TeamID string
-	Stats  Stats
+	RSCID  string `json:"rsc_id"`
+	Name   string `json:"name"`
+	TeamID string `json:"team_id"`
+	Stats  Stats  `json:"stats"`
 }
 
 // Stats holds stats about an entity
 type Stats struct {
 	// Record  Record
-	Goals   int
-	Assists int
-	Saves   int
-	Shots   int
+	Goals   int `json:"goals"`
+	Assists int `json:"assists"`
+	Saves   int `json:"saves"`
+	Shots   int `json:"shots"`
+}
+
+// APIKey describes a key used to authenticate with the API
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Owner     string     `json:"owner"`
+	RateLimit *int       `json:"rate_limit,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Revoked returns whether the key has been revoked
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
 }
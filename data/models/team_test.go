@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -15,3 +16,10 @@ func Test_Team_String(t *testing.T) {
 
 	require.Equal(t, "[The Bear Den] Care Bears (Master)", team.String())
 }
+
+func Test_APIKey_Revoked(t *testing.T) {
+	now := time.Now()
+
+	require.Equal(t, false, APIKey{}.Revoked())
+	require.Equal(t, true, APIKey{RevokedAt: &now}.Revoked())
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// WebhookSubscription is a url that gets sent events
+type WebhookSubscription struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+	// Secret is used to sign deliveries, it's only shown when the subscription is made
+	Secret string `json:"secret,omitempty"`
+	// EventTypes are the events sent to the url, all events if empty
+	EventTypes []EventType `json:"event_types"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// Wants returns whether the subscription should be sent events of the given type
+func (w WebhookSubscription) Wants(eventType EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a record of one attempt to send an event to a subscription
+type WebhookDelivery struct {
+	ID             int       `json:"id"`
+	SubscriptionID int       `json:"subscription_id"`
+	EventType      EventType `json:"event_type"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     *int      `json:"status_code,omitempty"`
+	Error          *string   `json:"error,omitempty"`
+	Success        bool      `json:"success"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+}
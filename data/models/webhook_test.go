@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WebhookSubscription_Wants(t *testing.T) {
+	all := WebhookSubscription{}
+	some := WebhookSubscription{EventTypes: []EventType{EventTeamAdded, EventTeamRemoved}}
+
+	require.Equal(t, true, all.Wants(EventStandingRecordChanged))
+	require.Equal(t, true, some.Wants(EventTeamRemoved))
+	require.Equal(t, false, some.Wants(EventStandingRecordChanged))
+}
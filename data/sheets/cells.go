@@ -0,0 +1,110 @@
+package sheets
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Ways the Sheets API can render cells, see https://developers.google.com/sheets/api/reference/rest/v4/ValueRenderOption
+const (
+	// UnformattedValue sends numbers and booleans as JSON numbers and booleans, so display formats like "55%" or "1,234" don't matter
+	UnformattedValue = "UNFORMATTED_VALUE"
+	// FormattedValue sends every cell as the text shown in the sheet
+	FormattedValue = "FORMATTED_VALUE"
+)
+
+// sentinels are cell values that mean there's no value
+var sentinels = map[string]bool{
+	"n/a": true,
+	"na":  true,
+	"-":   true,
+	"--":  true,
+	"—":   true,
+	"tbd": true,
+}
+
+// cellString reads a cell as text, trimming whitespace. Numbers and booleans are written the way they'd be typed.
+func cellString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("can't convert to string: %v", val)
+}
+
+// isBlank checks if a cell is empty or holds a sentinel like "N/A", "-" or "TBD"
+func isBlank(val interface{}) bool {
+	s, err := cellString(val)
+	return err == nil && (s == "" || sentinels[strings.ToLower(s)])
+}
+
+// cellOptionalString is cellString, with nil for blank cells
+func cellOptionalString(val interface{}) (*string, error) {
+	if isBlank(val) {
+		return nil, nil
+	}
+	s, err := cellString(val)
+	return &s, err
+}
+
+// cellFloat reads a number, "55%" is read as 0.55 and thousands separators are ignored.
+// ok is false for blank cells.
+func cellFloat(val interface{}) (f float64, ok bool, err error) {
+	if isBlank(val) {
+		return 0, false, nil
+	}
+	if f, isFloat := val.(float64); isFloat {
+		return f, true, nil
+	}
+
+	s, err := cellString(val)
+	if err != nil {
+		return 0, false, err
+	}
+	numStr := strings.ReplaceAll(s, ",", "")
+	percent := strings.HasSuffix(numStr, "%")
+	numStr = strings.TrimSpace(strings.TrimSuffix(numStr, "%"))
+
+	f, err = strconv.ParseFloat(numStr, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("not a number: %q", s)
+	}
+	if percent {
+		// parsing with the exponent gets the closest float to the fraction, dividing by 100 can be off by a bit
+		if fraction, err := strconv.ParseFloat(numStr+"e-2", 64); err == nil {
+			f = fraction
+		} else {
+			f /= 100
+		}
+	}
+	return f, true, nil
+}
+
+// cellRequiredInt is cellInt for columns that always have a value, blank cells are an error
+func cellRequiredInt(val interface{}) (int, error) {
+	i, ok, err := cellInt(val)
+	if err == nil && !ok {
+		return 0, fmt.Errorf("missing a number: %q", fmt.Sprint(val))
+	}
+	return i, err
+}
+
+// cellInt reads a whole number, ok is false for blank cells
+func cellInt(val interface{}) (i int, ok bool, err error) {
+	f, ok, err := cellFloat(val)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if f != math.Trunc(f) {
+		return 0, false, fmt.Errorf("not a whole number: %v", val)
+	}
+	return int(f), true, nil
+}
@@ -0,0 +1,108 @@
+package sheets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cellString(t *testing.T) {
+	tests := []struct {
+		val         interface{}
+		expected    string
+		expectedErr error
+	}{
+		{val: " Elite ", expected: "Elite"},
+		{val: float64(12), expected: "12"},
+		{val: 0.5, expected: "0.5"},
+		{val: true, expected: "true"},
+		{val: nil, expected: ""},
+		{val: 1, expectedErr: errors.New("can't convert to string: 1")},
+	}
+
+	for _, test := range tests {
+		s, err := cellString(test.val)
+		require.Equal(t, test.expectedErr, err, "%#v", test.val)
+		require.Equal(t, test.expected, s, "%#v", test.val)
+	}
+}
+
+func Test_cellOptionalString(t *testing.T) {
+	for _, blank := range []interface{}{nil, "", "  ", "N/A", "n/a", "-", "TBD"} {
+		s, err := cellOptionalString(blank)
+		require.NoError(t, err)
+		require.Nil(t, s, "%#v", blank)
+	}
+
+	s, err := cellOptionalString(" Blue")
+	require.NoError(t, err)
+	require.Equal(t, "Blue", *s)
+}
+
+func Test_cellFloat(t *testing.T) {
+	tests := []struct {
+		val         interface{}
+		expected    float64
+		expectedOK  bool
+		expectedErr error
+	}{
+		{val: 0.55, expected: 0.55, expectedOK: true},
+		{val: "55%", expected: 0.55, expectedOK: true},
+		{val: " 1,234.5 ", expected: 1234.5, expectedOK: true},
+		{val: "-3", expected: -3, expectedOK: true},
+		{val: "-"},
+		{val: ""},
+		{val: "abc", expectedErr: errors.New(`not a number: "abc"`)},
+	}
+
+	for _, test := range tests {
+		f, ok, err := cellFloat(test.val)
+		require.Equal(t, test.expectedErr, err, "%#v", test.val)
+		require.Equal(t, test.expectedOK, ok, "%#v", test.val)
+		require.InDelta(t, test.expected, f, 1e-9, "%#v", test.val)
+	}
+}
+
+func Test_cellInt(t *testing.T) {
+	tests := []struct {
+		val         interface{}
+		expected    int
+		expectedOK  bool
+		expectedErr error
+	}{
+		{val: float64(7), expected: 7, expectedOK: true},
+		{val: "12 ", expected: 12, expectedOK: true},
+		{val: "12.0", expected: 12, expectedOK: true},
+		{val: "N/A"},
+		{val: 2.5, expectedErr: errors.New("not a whole number: 2.5")},
+	}
+
+	for _, test := range tests {
+		i, ok, err := cellInt(test.val)
+		require.Equal(t, test.expectedErr, err, "%#v", test.val)
+		require.Equal(t, test.expectedOK, ok, "%#v", test.val)
+		require.Equal(t, test.expected, i, "%#v", test.val)
+	}
+}
+
+func Test_cellRequiredInt(t *testing.T) {
+	tests := []struct {
+		val         interface{}
+		expected    int
+		expectedErr error
+	}{
+		{val: float64(7), expected: 7},
+		{val: "0", expected: 0},
+		{val: nil, expectedErr: errors.New(`missing a number: "<nil>"`)},
+		{val: "", expectedErr: errors.New(`missing a number: ""`)},
+		{val: "N/A", expectedErr: errors.New(`missing a number: "N/A"`)},
+		{val: 2.5, expectedErr: errors.New("not a whole number: 2.5")},
+	}
+
+	for _, test := range tests {
+		i, err := cellRequiredInt(test.val)
+		require.Equal(t, test.expectedErr, err, "%#v", test.val)
+		require.Equal(t, test.expected, i, "%#v", test.val)
+	}
+}
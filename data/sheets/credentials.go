@@ -0,0 +1,104 @@
+package sheets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Ways to authenticate with the Sheets API
+const (
+	// AuthAPIKey only works for sheets shared publicly
+	AuthAPIKey = "api_key"
+	// AuthServiceAccount uses a service account's JSON key file, the sheet has to be shared with the account
+	AuthServiceAccount = "service_account"
+	// AuthTokenFile uses gcloud's authorized_user credentials, which hold a refresh token so a long running server keeps working
+	AuthTokenFile = "token_file"
+)
+
+// Credentials pick how a sheet is authenticated with the Sheets API
+type Credentials struct {
+	Method string
+	// APIKey is used with AuthAPIKey
+	APIKey string
+	// File is the credentials file used with AuthServiceAccount and AuthTokenFile
+	File string
+}
+
+// ClientOption loads the credentials, so that missing or invalid files are found before the first fetch
+func (c Credentials) ClientOption(ctx context.Context) (option.ClientOption, error) {
+	switch c.Method {
+	case AuthAPIKey:
+		if c.APIKey == "" {
+			return nil, errors.New("api_key auth needs an api key")
+		}
+		return option.WithAPIKey(c.APIKey), nil
+	case AuthServiceAccount:
+		data, err := c.readFile()
+		if err != nil {
+			return nil, err
+		}
+		var file struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("service account file %s isn't valid JSON: %v", c.File, err)
+		}
+		if file.Type != "service_account" {
+			return nil, fmt.Errorf("service account file %s has type %q, expected \"service_account\"", c.File, file.Type)
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, sheets.SpreadsheetsReadonlyScope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service account file %s: %v", c.File, err)
+		}
+		return option.WithCredentials(creds), nil
+	case AuthTokenFile:
+		data, err := c.readFile()
+		if err != nil {
+			return nil, err
+		}
+		return tokenFileOption(ctx, c.File, data)
+	}
+	return nil, fmt.Errorf("unknown auth method %q, must be one of %s, %s or %s", c.Method, AuthAPIKey, AuthServiceAccount, AuthTokenFile)
+}
+
+func (c Credentials) readFile() ([]byte, error) {
+	if c.File == "" {
+		return nil, fmt.Errorf("%s auth needs a credentials file", c.Method)
+	}
+	data, err := ioutil.ReadFile(c.File)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials file: %v", err)
+	}
+	return data, nil
+}
+
+// tokenFileOption reads gcloud's authorized_user credentials. A bare oauth2.Token is rejected, it expires
+// within an hour and can't be refreshed without the client it was issued to, so syncs would start failing.
+func tokenFileOption(ctx context.Context, path string, data []byte) (option.ClientOption, error) {
+	var file struct {
+		Type        string `json:"type"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("token file %s isn't valid JSON: %v", path, err)
+	}
+
+	if file.Type != "authorized_user" {
+		if file.AccessToken != "" {
+			return nil, fmt.Errorf("token file %s is a bare access token that can't be refreshed, use authorized_user credentials from `gcloud auth application-default login` instead", path)
+		}
+		return nil, fmt.Errorf("token file %s has type %q, expected \"authorized_user\"", path, file.Type)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, sheets.SpreadsheetsReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token file %s: %v", path, err)
+	}
+	return option.WithCredentials(creds), nil
+}
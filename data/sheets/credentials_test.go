@@ -0,0 +1,103 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Credentials_ClientOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+	serviceAccount := writeFile("sa.json", `{"type":"service_account","client_email":"sync@rsc.iam.gserviceaccount.com","private_key":"key","token_uri":"https://oauth2.googleapis.com/token"}`)
+	userCreds := writeFile("user.json", `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh"}`)
+	token := writeFile("token.json", `{"access_token":"abc","token_type":"Bearer","expiry":"2999-01-01T00:00:00Z"}`)
+	noAccessToken := writeFile("empty.json", `{"token_type":"Bearer"}`)
+	notJSON := writeFile("bad.json", `nope`)
+
+	tests := []struct {
+		name        string
+		creds       Credentials
+		expectedErr error
+	}{
+		{
+			name:  "api key",
+			creds: Credentials{Method: AuthAPIKey, APIKey: "key"},
+		},
+		{
+			name:        "missing api key",
+			creds:       Credentials{Method: AuthAPIKey},
+			expectedErr: errors.New("api_key auth needs an api key"),
+		},
+		{
+			name:  "service account",
+			creds: Credentials{Method: AuthServiceAccount, File: serviceAccount},
+		},
+		{
+			name:        "service account not json",
+			creds:       Credentials{Method: AuthServiceAccount, File: notJSON},
+			expectedErr: errors.New("service account file " + notJSON + " isn't valid JSON: invalid character 'o' in literal null (expecting 'u')"),
+		},
+		{
+			name:        "service account wrong type",
+			creds:       Credentials{Method: AuthServiceAccount, File: userCreds},
+			expectedErr: errors.New("service account file " + userCreds + ` has type "authorized_user", expected "service_account"`),
+		},
+		{
+			name:        "no file",
+			creds:       Credentials{Method: AuthServiceAccount},
+			expectedErr: errors.New("service_account auth needs a credentials file"),
+		},
+		{
+			name:  "authorized user token file",
+			creds: Credentials{Method: AuthTokenFile, File: userCreds},
+		},
+		{
+			name:        "bare token file",
+			creds:       Credentials{Method: AuthTokenFile, File: token},
+			expectedErr: errors.New("token file " + token + " is a bare access token that can't be refreshed, use authorized_user credentials from `gcloud auth application-default login` instead"),
+		},
+		{
+			name:        "token file without access token",
+			creds:       Credentials{Method: AuthTokenFile, File: noAccessToken},
+			expectedErr: errors.New("token file " + noAccessToken + ` has type "", expected "authorized_user"`),
+		},
+		{
+			name:        "service account as token file",
+			creds:       Credentials{Method: AuthTokenFile, File: serviceAccount},
+			expectedErr: errors.New("token file " + serviceAccount + ` has type "service_account", expected "authorized_user"`),
+		},
+		{
+			name:        "unknown method",
+			creds:       Credentials{Method: "password"},
+			expectedErr: errors.New(`unknown auth method "password", must be one of api_key, service_account or token_file`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opt, err := test.creds.ClientOption(context.Background())
+			if test.expectedErr != nil {
+				require.Equal(t, test.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, opt)
+		})
+	}
+
+	_, err = Credentials{Method: AuthTokenFile, File: filepath.Join(dir, "missing.json")}.ClientOption(context.Background())
+	require.Error(t, err)
+}
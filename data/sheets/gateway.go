@@ -0,0 +1,164 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Gateway fetches every range registered on a spreadsheet in one values:batchGet request,
+// so syncing several tabs only counts once against the Sheets API quota
+type Gateway struct {
+	sheetsService *sheets.Service
+	spreadsheetID string
+	// maxAge is how long a batch is reused for, long enough to cover every source in one sync
+	maxAge time.Duration
+	// RenderOption is how cells are sent, UnformattedValue by default so numbers don't depend on how they're displayed
+	RenderOption string
+	// Retry is how failed batches are retried
+	Retry RetryPolicy
+	// Breaker stops fetching for a while when the Sheets API keeps failing, nil to never stop
+	Breaker *CircuitBreaker
+	sleep   func(time.Duration)
+
+	// fetchMu lets one batch be fetched at a time, without holding mu while it retries
+	fetchMu   sync.Mutex
+	mu        sync.Mutex
+	ranges    []string
+	sheets    []string
+	values    [][][]interface{}
+	fetchedAt time.Time
+}
+
+// NewGateway makes a gateway for a spreadsheet, batches are reused by every range for maxAge
+func NewGateway(ctx context.Context, spreadsheetID string, creds Credentials, maxAge time.Duration) (*Gateway, error) {
+	auth, err := creds.ClientOption(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load %s credentials: %v", creds.Method, err)
+	}
+	svc, err := sheets.NewService(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+	return newGateway(svc, spreadsheetID, maxAge), nil
+}
+
+func newGateway(svc *sheets.Service, spreadsheetID string, maxAge time.Duration) *Gateway {
+	return &Gateway{
+		sheetsService: svc,
+		spreadsheetID: spreadsheetID,
+		maxAge:        maxAge,
+		RenderOption:  UnformattedValue,
+		Retry:         DefaultRetryPolicy,
+		Breaker:       NewCircuitBreaker(5, 5*time.Minute),
+		sleep:         time.Sleep,
+	}
+}
+
+// SpreadsheetID returns the ID of the spreadsheet the gateway fetches from
+func (g *Gateway) SpreadsheetID() string {
+	return g.spreadsheetID
+}
+
+// Range is one range of a Gateway's spreadsheet
+type Range struct {
+	gateway *Gateway
+	index   int
+}
+
+// AddRange registers the columns of a tab, like "A:S", to be fetched with every batch.
+// Leave columns empty to fetch the whole tab.
+func (g *Gateway) AddRange(sheetName, columns string) Range {
+	a1 := "'" + strings.ReplaceAll(sheetName, "'", "''") + "'"
+	if columns != "" {
+		a1 += "!" + columns
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ranges = append(g.ranges, a1)
+	g.sheets = append(g.sheets, sheetName)
+	// the cached batch doesn't have the new range
+	g.fetchedAt = time.Time{}
+	return Range{gateway: g, index: len(g.ranges) - 1}
+}
+
+// GetValues returns the range's cells from the latest batch, fetching a new one if it's older than maxAge
+func (r Range) GetValues() ([][]interface{}, error) {
+	return r.gateway.get(r.index)
+}
+
+func (g *Gateway) get(index int) ([][]interface{}, error) {
+	if values, ok := g.cached(index); ok {
+		return values, nil
+	}
+
+	g.fetchMu.Lock()
+	defer g.fetchMu.Unlock()
+	// another reader may have fetched the batch while this one waited
+	if values, ok := g.cached(index); ok {
+		return values, nil
+	}
+
+	g.mu.Lock()
+	ranges := append([]string(nil), g.ranges...)
+	sheetNames := append([]string(nil), g.sheets...)
+	g.mu.Unlock()
+
+	values, err := g.batchGet(ranges, sheetNames)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// a range added during the fetch isn't in this batch, the next get fetches it
+	if len(g.ranges) == len(ranges) {
+		g.values = values
+		g.fetchedAt = time.Now()
+	}
+	return values[index], nil
+}
+
+// cached returns the range's cells if the latest batch has them and isn't older than maxAge
+func (g *Gateway) cached(index int) ([][]interface{}, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.fetchedAt.IsZero() || time.Since(g.fetchedAt) > g.maxAge || index >= len(g.values) {
+		return nil, false
+	}
+	return g.values[index], true
+}
+
+// batchGet fetches ranges, retrying with backoff. It doesn't touch the gateway's state so mu isn't held while it sleeps.
+func (g *Gateway) batchGet(ranges, sheetNames []string) ([][][]interface{}, error) {
+	var result *sheets.BatchGetValuesResponse
+	err := call(g.Retry, g.Breaker, g.sleep, func() error {
+		start := time.Now()
+		var err error
+		result, err = g.sheetsService.Spreadsheets.Values.BatchGet(g.spreadsheetID).Ranges(ranges...).ValueRenderOption(g.RenderOption).Do()
+		if err == nil && len(result.ValueRanges) != len(ranges) {
+			err = fmt.Errorf("asked for %d ranges but got %d", len(ranges), len(result.ValueRanges))
+		}
+		for _, sheet := range sheetNames {
+			metrics.ObserveSheetFetch(sheet, start, err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// value ranges come back in the order they were asked for
+	values := make([][][]interface{}, len(result.ValueRanges))
+	for i, vr := range result.ValueRanges {
+		values[i] = vr.Values
+	}
+	return values, nil
+}
@@ -0,0 +1,149 @@
+package sheets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// newTestGateway serves values:batchGet, answering each range with values[range]
+func newTestGateway(t *testing.T, values map[string][][]interface{}, maxAge time.Duration) (*Gateway, *[][]string) {
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v4/spreadsheets/abc123/values:batchGet", r.URL.Path)
+		require.Equal(t, UnformattedValue, r.URL.Query().Get("valueRenderOption"))
+		ranges := r.URL.Query()["ranges"]
+		requests = append(requests, ranges)
+
+		resp := sheets.BatchGetValuesResponse{SpreadsheetId: "abc123"}
+		for _, rng := range ranges {
+			resp.ValueRanges = append(resp.ValueRanges, &sheets.ValueRange{Range: rng, Values: values[rng]})
+		}
+		json.NewEncoder(w).Encode(&resp)
+	}))
+	t.Cleanup(server.Close)
+
+	svc, err := sheets.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+	return newGateway(svc, "abc123", maxAge), &requests
+}
+
+func Test_Gateway(t *testing.T) {
+	values := map[string][][]interface{}{
+		"'All Teams Data'!A:S": {{"standings"}},
+		"'Players'!A:F":        {{"players"}},
+		"'Bob''s Tab'":         {{"bob"}},
+	}
+	gateway, requests := newTestGateway(t, values, time.Minute)
+
+	standings := gateway.AddRange("All Teams Data", "A:S")
+	players := gateway.AddRange("Players", "A:F")
+	bob := gateway.AddRange("Bob's Tab", "")
+
+	got, err := standings.GetValues()
+	require.NoError(t, err)
+	require.Equal(t, values["'All Teams Data'!A:S"], got)
+	got, err = players.GetValues()
+	require.NoError(t, err)
+	require.Equal(t, values["'Players'!A:F"], got)
+	got, err = bob.GetValues()
+	require.NoError(t, err)
+	require.Equal(t, values["'Bob''s Tab'"], got)
+
+	require.Equal(t, [][]string{{"'All Teams Data'!A:S", "'Players'!A:F", "'Bob''s Tab'"}}, *requests)
+}
+
+func Test_Gateway_maxAge(t *testing.T) {
+	gateway, requests := newTestGateway(t, map[string][][]interface{}{}, 0)
+	r := gateway.AddRange("All Teams Data", "A:S")
+
+	_, err := r.GetValues()
+	require.NoError(t, err)
+	_, err = r.GetValues()
+	require.NoError(t, err)
+	require.Len(t, *requests, 2)
+}
+
+func Test_TeamStandingsSheet_Gateway(t *testing.T) {
+	gateway, _ := newTestGateway(t, map[string][][]interface{}{"'All Teams Data'!A:S": snapshotValues}, time.Minute)
+	sheet := NewTeamStandingsSheet(gateway, "All Teams Data")
+
+	require.Equal(t, "abc123", sheet.SpreadsheetID())
+	standings, err := sheet.GetTeamStandingsFromSheet()
+	require.NoError(t, err)
+	require.Equal(t, snapshotStandings, standings)
+}
+
+func Test_Gateway_retries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(&sheets.BatchGetValuesResponse{ValueRanges: []*sheets.ValueRange{{Values: [][]interface{}{{"a"}}}}})
+	}))
+	defer server.Close()
+
+	svc, err := sheets.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+	gateway := newGateway(svc, "abc123", time.Minute)
+	gateway.sleep = func(time.Duration) {}
+
+	values, err := gateway.AddRange("All Teams Data", "A:S").GetValues()
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{{"a"}}, values)
+	require.Equal(t, 3, calls)
+}
+
+func Test_Gateway_readsWhileRetrying(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(&sheets.BatchGetValuesResponse{ValueRanges: []*sheets.ValueRange{{Values: [][]interface{}{{"a"}}}}})
+	}))
+	defer server.Close()
+
+	svc, err := sheets.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+	gateway := newGateway(svc, "abc123", time.Minute)
+	teams := gateway.AddRange("All Teams Data", "A:S")
+	_, err = teams.GetValues()
+	require.NoError(t, err)
+
+	// while a fetch for a new range is backing off the cached range can still be read
+	sleeping := make(chan struct{})
+	resume := make(chan struct{})
+	gateway.sleep = func(time.Duration) {
+		sleeping <- struct{}{}
+		<-resume
+	}
+	gateway.Retry.MaxRetries = 1
+	players := gateway.AddRange("Players", "")
+	gateway.fetchedAt = time.Now()
+
+	fetched := make(chan error)
+	go func() {
+		_, err := players.GetValues()
+		fetched <- err
+	}()
+	<-sleeping
+	values, err := teams.GetValues()
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{{"a"}}, values)
+
+	close(resume)
+	require.Error(t, <-fetched)
+}
@@ -0,0 +1,164 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrCircuitOpen is returned without calling the Sheets API while the circuit breaker is open
+var ErrCircuitOpen = errors.New("sheets api circuit breaker is open after repeated failures")
+
+// RetryPolicy is how failed Sheets API calls are retried
+type RetryPolicy struct {
+	// MaxRetries is how many times a call is retried after a 429 or 5xx
+	MaxRetries int
+	// BaseDelay is the wait before the first retry, it doubles for every retry after
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy retries 4 times, waiting about 1s, 2s, 4s and 8s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 4,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// delay is how long to wait before retry number attempt, starting at 1, with up to 50% jitter
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryable checks if err means the Sheets API is down or overloaded: a quota or server error,
+// or a network failure like a refused connection, timeout or DNS error. Cancelling the call isn't one.
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
+// retryAfter is how long a 429 response asked to wait before retrying, 0 if it didn't say
+func retryAfter(err error) time.Duration {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		return 0
+	}
+	v := apiErr.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// CircuitBreaker stops calls to the Sheets API for a cooldown after too many failed requests in a row,
+// then lets a single trial request through to check if it's back
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	// probing is set while the trial request after a cooldown is in flight
+	probing bool
+}
+
+// NewCircuitBreaker makes a breaker that opens after threshold failed requests in a row
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow returns ErrCircuitOpen if a request should not be made. Once the cooldown is over
+// it allows one trial request, every other caller is turned away until its result is recorded.
+func (c *CircuitBreaker) Allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.openedAt.IsZero() {
+		return nil
+	}
+	if c.probing || c.now().Sub(c.openedAt) < c.cooldown {
+		return ErrCircuitOpen
+	}
+	c.probing = true
+	return nil
+}
+
+// Record counts the result of one request. Any response other than a quota or server error,
+// or a network failure, means the API is up and closes the breaker.
+func (c *CircuitBreaker) Record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	if !retryable(err) {
+		c.failures = 0
+		c.openedAt = time.Time{}
+		return
+	}
+	c.failures++
+	if c.failures >= c.threshold {
+		// reopening after a failed trial request restarts the cooldown
+		c.openedAt = c.now()
+	}
+}
+
+// call runs f with retries, giving up without calling it if the breaker is open.
+// Every attempt is a request the breaker counts, so a long outage opens it partway through a call's retries.
+// Retries wait at least as long as a 429's Retry-After header asks.
+func call(policy RetryPolicy, breaker *CircuitBreaker, sleep func(time.Duration), f func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if breaker != nil {
+			if openErr := breaker.Allow(); openErr != nil {
+				if err != nil {
+					// the breaker opened during this call's retries, the last failure says why
+					return err
+				}
+				return openErr
+			}
+		}
+
+		err = f()
+		if breaker != nil {
+			breaker.Record(err)
+		}
+		if err == nil || !retryable(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		delay := policy.delay(attempt + 1)
+		if wait := retryAfter(err); wait > delay {
+			delay = wait
+		}
+		sleep(delay)
+	}
+}
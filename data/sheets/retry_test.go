@@ -0,0 +1,163 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+func Test_call(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	errQuota := &googleapi.Error{Code: http.StatusTooManyRequests}
+	errDown := &googleapi.Error{Code: http.StatusServiceUnavailable}
+	errNotFound := &googleapi.Error{Code: http.StatusNotFound}
+
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "success",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			name:          "retries quota and server errors",
+			errs:          []error{errQuota, errDown, nil},
+			expectedCalls: 3,
+		},
+		{
+			name:          "gives up",
+			errs:          []error{errDown, errDown, errDown, errDown, errDown},
+			expectedCalls: 4,
+			expectedErr:   errDown,
+		},
+		{
+			name:          "doesn't retry other errors",
+			errs:          []error{errNotFound, nil},
+			expectedCalls: 1,
+			expectedErr:   errNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			var slept []time.Duration
+			err := call(policy, nil, func(d time.Duration) { slept = append(slept, d) }, func() error {
+				calls++
+				return test.errs[calls-1]
+			})
+
+			require.Equal(t, test.expectedErr, err)
+			require.Equal(t, test.expectedCalls, calls)
+			require.Len(t, slept, calls-1)
+			for i, d := range slept {
+				max := policy.BaseDelay << uint(i)
+				require.True(t, d >= max/2 && d <= max, "retry %d waited %s", i+1, d)
+			}
+		})
+	}
+}
+
+func Test_CircuitBreaker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	errDown := &googleapi.Error{Code: http.StatusServiceUnavailable}
+
+	breaker.Record(errDown)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(errDown)
+	require.Equal(t, ErrCircuitOpen, breaker.Allow())
+
+	// one trial request is let through after the cooldown, failing it reopens the breaker
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	require.Equal(t, ErrCircuitOpen, breaker.Allow())
+	breaker.Record(errDown)
+	require.Equal(t, ErrCircuitOpen, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Record(nil)
+	breaker.Record(errDown)
+	require.NoError(t, breaker.Allow())
+
+	// errors that mean the API is up don't count
+	breaker.Record(nil)
+	breaker.Record(&googleapi.Error{Code: http.StatusForbidden})
+	breaker.Record(errors.New("asked for 2 ranges but got 1"))
+	breaker.Record(errDown)
+	require.NoError(t, breaker.Allow())
+}
+
+func Test_call_breakerOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(3, time.Hour)
+	errServer := &googleapi.Error{Code: http.StatusInternalServerError}
+	calls := 0
+	failing := func() error {
+		calls++
+		return errServer
+	}
+
+	// every attempt counts, so the breaker opens partway through the retries
+	err := call(DefaultRetryPolicy, breaker, func(time.Duration) {}, failing)
+	require.Equal(t, errServer, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = call(DefaultRetryPolicy, breaker, func(time.Duration) {}, failing)
+	require.Equal(t, ErrCircuitOpen, err)
+	require.Equal(t, 0, calls)
+}
+
+func Test_call_retryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 1, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	errQuota := &googleapi.Error{Code: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"20"}}}
+
+	calls := 0
+	var slept []time.Duration
+	err := call(policy, nil, func(d time.Duration) { slept = append(slept, d) }, func() error {
+		calls++
+		if calls == 1 {
+			return errQuota
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{20 * time.Second}, slept)
+}
+
+func Test_retryable(t *testing.T) {
+	timeout := &net.DNSError{Err: "timeout", Name: "sheets.googleapis.com", IsTimeout: true}
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "quota", err: &googleapi.Error{Code: http.StatusTooManyRequests}, expected: true},
+		{name: "server error", err: &googleapi.Error{Code: http.StatusBadGateway}, expected: true},
+		{name: "not found", err: &googleapi.Error{Code: http.StatusNotFound}, expected: false},
+		{name: "connection refused", err: &url.Error{Op: "Get", URL: "https://sheets.googleapis.com", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, expected: true},
+		{name: "dns error", err: timeout, expected: true},
+		{name: "cancelled", err: &url.Error{Op: "Get", URL: "https://sheets.googleapis.com", Err: context.Canceled}, expected: false},
+		{name: "deadline", err: context.DeadlineExceeded, expected: false},
+		{name: "other", err: errors.New("asked for 2 ranges but got 1"), expected: false},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, retryable(test.err), "%q wrong retryable", test.name)
+	}
+}
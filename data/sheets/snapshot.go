@@ -0,0 +1,214 @@
+package sheets
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
+// Snapshot formats, picked by the file extension
+const (
+	SnapshotJSON = "json"
+	SnapshotCSV  = "csv"
+	SnapshotXLSX = "xlsx"
+)
+
+// Snapshot is a copy of every cell of a sheet, so it can be served without the Sheets API.
+// Values are kept as the Sheets API sent them, strings, float64s, bools or nil.
+type Snapshot struct {
+	SpreadsheetID string          `json:"spreadsheet_id"`
+	SheetName     string          `json:"sheet_name"`
+	CapturedAt    time.Time       `json:"captured_at"`
+	Values        [][]interface{} `json:"values"`
+}
+
+// NewSnapshot makes a snapshot of the values fetched from a sheet
+func NewSnapshot(spreadsheetID, sheetName string, values [][]interface{}) Snapshot {
+	return Snapshot{
+		SpreadsheetID: spreadsheetID,
+		SheetName:     sheetName,
+		CapturedAt:    time.Now().UTC(),
+		Values:        values,
+	}
+}
+
+// SnapshotFormat gets the format of a snapshot file from its extension
+func SnapshotFormat(path string) (string, error) {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch format {
+	case SnapshotJSON, SnapshotCSV, SnapshotXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown snapshot format %q, must be .json, .csv or .xlsx", filepath.Ext(path))
+}
+
+// Write writes the snapshot in format. Only JSON keeps the spreadsheet id, capture time and the types of the
+// values, CSV and XLSX are read back as text like a sheet fetched with FormattedValue.
+func (s Snapshot) Write(w io.Writer, format string) error {
+	switch format {
+	case SnapshotJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&s)
+	case SnapshotCSV:
+		cw := csv.NewWriter(w)
+		for _, row := range s.Values {
+			record := make([]string, len(row))
+			for j, val := range row {
+				str, err := cellString(val)
+				if err != nil {
+					return err
+				}
+				record[j] = str
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case SnapshotXLSX:
+		f := excelize.NewFile()
+		sheetName := s.SheetName
+		if sheetName == "" {
+			sheetName = "Sheet1"
+		}
+		f.SetSheetName("Sheet1", sheetName)
+		for i, row := range s.Values {
+			row := row
+			axis, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheetName, axis, &row); err != nil {
+				return err
+			}
+		}
+		return f.Write(w)
+	}
+	return fmt.Errorf("unknown snapshot format: %s", format)
+}
+
+// ReadSnapshot reads a snapshot written in format
+func ReadSnapshot(r io.Reader, format string) (Snapshot, error) {
+	var s Snapshot
+	switch format {
+	case SnapshotJSON:
+		if err := json.NewDecoder(r).Decode(&s); err != nil {
+			return s, err
+		}
+	case SnapshotCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		values, err := cr.ReadAll()
+		if err != nil {
+			return s, err
+		}
+		s.Values = stringValues(values)
+	case SnapshotXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return s, err
+		}
+		s.SheetName = f.GetSheetName(0)
+		values, err := f.GetRows(s.SheetName)
+		if err != nil {
+			return s, err
+		}
+		s.Values = stringValues(values)
+	default:
+		return s, fmt.Errorf("unknown snapshot format: %s", format)
+	}
+
+	// the Sheets API leaves off empty cells at the end of rows, spreadsheet files pad them
+	for i, row := range s.Values {
+		end := len(row)
+		for end > 0 && (row[end-1] == nil || row[end-1] == "") {
+			end--
+		}
+		s.Values[i] = row[:end]
+	}
+	return s, nil
+}
+
+// stringValues converts the text cells of a spreadsheet file into sheet values
+func stringValues(rows [][]string) [][]interface{} {
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, val := range row {
+			values[i][j] = val
+		}
+	}
+	return values
+}
+
+// SnapshotSheet is a TeamStandingsRetriever that reads a snapshot file instead of the Sheets API.
+// The file is read again on every sync so edits to it are picked up.
+type SnapshotSheet struct {
+	path          string
+	format        string
+	spreadsheetID string
+	sheetName     string
+}
+
+// NewSnapshotSheet checks the snapshot at path can be read and makes a retriever for it
+func NewSnapshotSheet(path string) (*SnapshotSheet, error) {
+	format, err := SnapshotFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &SnapshotSheet{path: path, format: format}
+
+	s, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+	t.spreadsheetID, t.sheetName = s.SpreadsheetID, s.SheetName
+	if t.spreadsheetID == "" {
+		t.spreadsheetID = path
+	}
+	if t.sheetName == "" {
+		t.sheetName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return t, nil
+}
+
+func (t *SnapshotSheet) read() (Snapshot, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+
+	s, err := ReadSnapshot(f, t.format)
+	if err != nil {
+		return s, fmt.Errorf("error reading snapshot %s: %v", t.path, err)
+	}
+	return s, nil
+}
+
+// SpreadsheetID returns the ID of the spreadsheet the snapshot was captured from, or its path if it isn't known
+func (t *SnapshotSheet) SpreadsheetID() string {
+	return t.spreadsheetID
+}
+
+// SheetName returns the name of the tab the snapshot was captured from
+func (t *SnapshotSheet) SheetName() string {
+	return t.sheetName
+}
+
+func (t *SnapshotSheet) GetTeamStandingsFromSheet() ([]TeamStanding, error) {
+	s, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+	return valuesToTeamStandings(t.sheetName, s.Values), nil
+}
@@ -0,0 +1,106 @@
+package sheets
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+var snapshotValues = [][]interface{}{
+	{"", "", "", "", "", "", "", "Overall"},
+	{"Tier", "Franchise", "Team", "Conference", "Division", "", "", "W", "L"},
+	{"Elite", "Space", "Rockets", "Solar", "Blue", "", "", float64(5), float64(2), "", "", "", float64(3), float64(1), "", "", "", float64(2), float64(0)},
+	{"Major", "Sky", "Comets", "Lunar", "N/A", nil, "", "1", "6", "", "", "", "0", "4"},
+	{"Major", "Sky", "Broken", "Lunar", "N/A", "", "", "x"},
+}
+
+var snapshotStandings = []TeamStanding{
+	{
+		Team:             models.Team{Tier: "Elite", Franchise: "Space", Name: "Rockets", Conference: "Solar", Division: stringPtr("Blue")},
+		OverallRecord:    Record{Wins: 5, Losses: 2},
+		ConferenceRecord: Record{Wins: 3, Losses: 1},
+		DivisionRecord:   &Record{Wins: 2, Losses: 0},
+	},
+	{
+		Team:             models.Team{Tier: "Major", Franchise: "Sky", Name: "Comets", Conference: "Lunar"},
+		OverallRecord:    Record{Wins: 1, Losses: 6},
+		ConferenceRecord: Record{Wins: 0, Losses: 4},
+	},
+}
+
+func Test_SnapshotSheet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	snapshot := NewSnapshot("abc123", "All Teams Data", snapshotValues)
+
+	tests := []struct {
+		file                  string
+		expectedSpreadsheetID string
+		expectedSheetName     string
+	}{
+		{
+			file:                  "teams.json",
+			expectedSpreadsheetID: "abc123",
+			expectedSheetName:     "All Teams Data",
+		},
+		{
+			file:                  "teams.csv",
+			expectedSpreadsheetID: filepath.Join(dir, "teams.csv"),
+			expectedSheetName:     "teams",
+		},
+		{
+			file:                  "teams.xlsx",
+			expectedSpreadsheetID: filepath.Join(dir, "teams.xlsx"),
+			expectedSheetName:     "All Teams Data",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			path := filepath.Join(dir, test.file)
+			format, err := SnapshotFormat(path)
+			require.NoError(t, err)
+
+			f, err := os.Create(path)
+			require.NoError(t, err)
+			require.NoError(t, snapshot.Write(f, format))
+			require.NoError(t, f.Close())
+
+			sheet, err := NewSnapshotSheet(path)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSpreadsheetID, sheet.SpreadsheetID())
+			require.Equal(t, test.expectedSheetName, sheet.SheetName())
+
+			standings, err := sheet.GetTeamStandingsFromSheet()
+			require.NoError(t, err)
+			require.Equal(t, snapshotStandings, standings)
+		})
+	}
+}
+
+func Test_NewSnapshotSheet_errors(t *testing.T) {
+	_, err := NewSnapshotSheet("teams.txt")
+	require.EqualError(t, err, `unknown snapshot format ".txt", must be .json, .csv or .xlsx`)
+
+	_, err = NewSnapshotSheet(filepath.Join(os.TempDir(), "does-not-exist.json"))
+	require.Error(t, err)
+}
+
+func Test_Snapshot_JSONKeepsTypes(t *testing.T) {
+	snapshot := NewSnapshot("abc123", "All Teams Data", [][]interface{}{
+		{"Rockets", float64(5), 0.55, true, nil, "5"},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.Write(&buf, SnapshotJSON))
+	read, err := ReadSnapshot(&buf, SnapshotJSON)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Values, read.Values)
+}
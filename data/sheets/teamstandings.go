@@ -1,148 +1,192 @@
 package sheets
 
 import (
-	"context"
 	"fmt"
-	"strconv"
+	"strings"
 
 	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/option"
-	"google.golang.org/api/sheets/v4"
 )
 
 const TEAMSTANDINGSHEADERS = 2
 
 type TeamStandingsRetriever interface {
 	GetTeamStandingsFromSheet() ([]TeamStanding, error)
+	SpreadsheetID() string
+	SheetName() string
 }
 
+// teamStandingsColumns are the columns of the standings tab that are parsed
+const teamStandingsColumns = "A:S"
+
 type TeamStandingsSheet struct {
-	sheetsService *sheets.Service
+	values        Range
 	spreadsheetID string
 	sheetName     string
 }
 
-func NewTeamStandingsSheet(ctx context.Context, spreadsheetID, sheetName, apiKey string) (*TeamStandingsSheet, error) {
-	svc, err := sheets.NewService(ctx, option.WithAPIKey(apiKey))
+// NewTeamStandingsSheet registers the standings tab's columns on gateway
+func NewTeamStandingsSheet(gateway *Gateway, sheetName string) *TeamStandingsSheet {
 	return &TeamStandingsSheet{
-		sheetsService: svc,
-		spreadsheetID: spreadsheetID,
+		values:        gateway.AddRange(sheetName, teamStandingsColumns),
+		spreadsheetID: gateway.SpreadsheetID(),
 		sheetName:     sheetName,
-	}, err
+	}
+}
+
+// SpreadsheetID returns the ID of the spreadsheet the standings come from
+func (t TeamStandingsSheet) SpreadsheetID() string {
+	return t.spreadsheetID
+}
+
+// SheetName returns the name of the tab the standings come from
+func (t TeamStandingsSheet) SheetName() string {
+	return t.sheetName
+}
+
+// GetValues fetches the parsed columns of the sheet, header rows included
+func (t TeamStandingsSheet) GetValues() ([][]interface{}, error) {
+	return t.values.GetValues()
 }
 
 func (t TeamStandingsSheet) GetTeamStandingsFromSheet() ([]TeamStanding, error) {
-	result, err := t.sheetsService.Spreadsheets.Values.Get(t.spreadsheetID, t.sheetName).Do()
+	values, err := t.GetValues()
 	if err != nil {
 		return nil, err
 	}
+	return valuesToTeamStandings(t.sheetName, values), nil
+}
 
-	rows := result.Values[TEAMSTANDINGSHEADERS:]
-
+// valuesToTeamStandings converts the rows after the headers, rows that fail to convert are logged and skipped
+func valuesToTeamStandings(sheetName string, values [][]interface{}) []TeamStanding {
+	if len(values) < TEAMSTANDINGSHEADERS {
+		return []TeamStanding{}
+	}
+	rows := values[TEAMSTANDINGSHEADERS:]
 	standings := make([]TeamStanding, 0, len(rows))
+	rejected := 0
 	for i, row := range rows {
+		if isBlankRow(row) {
+			// spacer rows between tiers
+			continue
+		}
 		standing, err := rowToTeamStanding(row)
 		if err != nil {
-			log.Errorf("Row %d failed to be converted: %v", i, row)
+			log.Errorf("Row %d failed to be converted: %v: %v", i, err, row)
+			rejected++
 			continue
 		}
 		standings = append(standings, standing)
 	}
-
-	return standings, nil
+	metrics.AddRowsRejected(sheetName, rejected)
+	return standings
 }
 
 // TeamStanding holds standing stats about a current Team
-type TeamStanding struct {
-	Team             models.Team
-	OverallRecord    Record
-	ConferenceRecord Record
-	DivisionRecord   *Record
-}
+type TeamStanding = models.Standing
 
 // Record holds Wins and Losses
-type Record struct {
-	Wins   int
-	Losses int
-}
+type Record = models.Record
 
+// setTeamStandingValBasedOnColumn sets the field read from a column of the standings tab. The columns are
+// tier, franchise, team, conference, division, seed, games behind, wins, losses, win %, game differential,
+// streak, conference wins, conference losses, conference win %, series wins, series losses,
+// division wins and division losses. Conference win % is left out since it comes from the conference record.
 func setTeamStandingValBasedOnColumn(t *TeamStanding, colIndex int, val interface{}) error {
-	valStr, err := assertToString(val)
-	if err != nil {
-		return err
-	}
-
+	var err error
 	switch colIndex {
 	case 0:
-		t.Team.Tier = valStr
+		t.Team.Tier, err = cellString(val)
 	case 1:
-		t.Team.Franchise = valStr
+		t.Team.Franchise, err = cellString(val)
 	case 2:
-		t.Team.Name = valStr
+		t.Team.Name, err = cellString(val)
 	case 3:
-		t.Team.Conference = valStr
+		t.Team.Conference, err = cellString(val)
 	case 4:
-		if valStr == "N/A" {
-			t.Team.Division = nil
-		} else {
-			t.Team.Division = &valStr
+		t.Team.Division, err = cellOptionalString(val)
+	case 5:
+		var seed int
+		var ok bool
+		if seed, ok, err = cellInt(val); ok {
+			t.Seed = &seed
 		}
+	case 6:
+		// the conference leader's games behind is "-", which is read as 0
+		t.GamesBehind, _, err = cellFloat(val)
 	case 7:
-		wins, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
-		}
-		t.OverallRecord.Wins = wins
+		t.OverallRecord.Wins, err = cellRequiredInt(val)
 	case 8:
-		losses, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
+		t.OverallRecord.Losses, err = cellRequiredInt(val)
+	case 9:
+		t.WinPercentage, _, err = cellFloat(val)
+	case 10:
+		t.GameDifferential, _, err = cellInt(val)
+	case 11:
+		var streak *string
+		if streak, err = cellOptionalString(val); streak != nil {
+			t.Streak = strings.ToUpper(*streak)
 		}
-		t.OverallRecord.Losses = losses
 	case 12:
-		wins, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
-		}
-		t.ConferenceRecord.Wins = wins
+		t.ConferenceRecord.Wins, err = cellRequiredInt(val)
 	case 13:
-		losses, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
-		}
-		t.ConferenceRecord.Losses = losses
+		t.ConferenceRecord.Losses, err = cellRequiredInt(val)
+	case 15:
+		t.SeriesRecord.Wins, _, err = cellInt(val)
+	case 16:
+		t.SeriesRecord.Losses, _, err = cellInt(val)
 	case 17:
-		if valStr == "" {
-			return nil
-		}
-		wins, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
-		}
-		if t.DivisionRecord == nil {
-			t.DivisionRecord = &Record{}
-		}
-		t.DivisionRecord.Wins = wins
+		err = setDivisionRecordVal(t, val, func(r *Record, n int) { r.Wins = n })
 	case 18:
-		if valStr == "" {
-			return nil
-		}
-		losses, err := strconv.Atoi(valStr)
-		if err != nil {
-			return err
-		}
-		if t.DivisionRecord == nil {
-			t.DivisionRecord = &Record{}
-		}
-		t.DivisionRecord.Losses = losses
+		err = setDivisionRecordVal(t, val, func(r *Record, n int) { r.Losses = n })
+	}
+	return err
+}
+
+// setDivisionRecordVal sets part of the division record, teams without a division have blank cells and no record
+func setDivisionRecordVal(t *TeamStanding, val interface{}, set func(r *Record, n int)) error {
+	n, ok, err := cellInt(val)
+	if err != nil || !ok {
+		return err
+	}
+	if t.DivisionRecord == nil {
+		t.DivisionRecord = &Record{}
 	}
+	set(t.DivisionRecord, n)
 	return nil
 }
 
+// requiredColumns are the columns every team has to have a value in: tier, franchise, team,
+// wins, losses, conference wins and conference losses
+var requiredColumns = map[int]string{0: "tier", 1: "franchise", 2: "team", 7: "wins", 8: "losses", 12: "conference wins", 13: "conference losses"}
+
+// minTeamStandingColumns is how many columns a row needs to have every required column.
+// The Sheets API leaves out blank cells at the end of a row, so shorter rows are missing some.
+const minTeamStandingColumns = 14
+
+// isBlankRow checks if every cell of a row is blank
+func isBlankRow(row []interface{}) bool {
+	for _, val := range row {
+		if !isBlank(val) {
+			return false
+		}
+	}
+	return true
+}
+
 func rowToTeamStanding(row []interface{}) (TeamStanding, error) {
 	standing := TeamStanding{}
 
+	if len(row) < minTeamStandingColumns {
+		return standing, fmt.Errorf("row has %d columns, expected at least %d", len(row), minTeamStandingColumns)
+	}
+	for i := 0; i < minTeamStandingColumns; i++ {
+		if name, ok := requiredColumns[i]; ok && isBlank(row[i]) {
+			return standing, fmt.Errorf("missing %s: %q", name, fmt.Sprint(row[i]))
+		}
+	}
+
 	for i, val := range row {
 		err := setTeamStandingValBasedOnColumn(&standing, i, val)
 		if err != nil {
@@ -152,10 +196,3 @@ func rowToTeamStanding(row []interface{}) (TeamStanding, error) {
 
 	return standing, nil
 }
-
-func assertToString(val interface{}) (string, error) {
-	if valStr, ok := val.(string); ok {
-		return valStr, nil
-	}
-	return "", fmt.Errorf("can't convert to string: %v", val)
-}
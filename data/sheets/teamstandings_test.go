@@ -2,6 +2,7 @@ package sheets
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
@@ -21,12 +22,19 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 		expectedErr error
 	}{
 		{
-			name:        "not a string",
+			name:        "not a cell type",
 			colIndex:    0,
 			val:         interface{}(1),
 			expectedT:   &TeamStanding{},
 			expectedErr: errors.New("can't convert to string: 1"),
 		},
+		{
+			name:        "Tier padded",
+			colIndex:    0,
+			val:         interface{}(" Elite "),
+			expectedT:   &TeamStanding{Team: models.Team{Tier: "Elite"}},
+			expectedErr: nil,
+		},
 		{
 			name:        "Tier",
 			colIndex:    0,
@@ -76,12 +84,47 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			expectedT:   &TeamStanding{OverallRecord: Record{Wins: 1}},
 			expectedErr: nil,
 		},
+		{
+			name:        "Overall.Wins unformatted",
+			colIndex:    7,
+			val:         interface{}(float64(12)),
+			expectedT:   &TeamStanding{OverallRecord: Record{Wins: 12}},
+			expectedErr: nil,
+		},
+		{
+			name:        "Overall.Wins whitespace",
+			colIndex:    7,
+			val:         interface{}("12 "),
+			expectedT:   &TeamStanding{OverallRecord: Record{Wins: 12}},
+			expectedErr: nil,
+		},
+		{
+			name:        "Overall.Wins sentinel",
+			colIndex:    7,
+			val:         interface{}("-"),
+			expectedT:   &TeamStanding{},
+			expectedErr: errors.New(`missing a number: "-"`),
+		},
+		{
+			name:        "Overall.Losses empty str",
+			colIndex:    8,
+			val:         interface{}(""),
+			expectedT:   &TeamStanding{},
+			expectedErr: errors.New(`missing a number: ""`),
+		},
+		{
+			name:        "ConferenceRecord.Wins TBD",
+			colIndex:    12,
+			val:         interface{}("TBD"),
+			expectedT:   &TeamStanding{},
+			expectedErr: errors.New(`missing a number: "TBD"`),
+		},
 		{
 			name:        "Overall.Wins not a number",
 			colIndex:    7,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 		{
 			name:        "Overall.Losses",
@@ -95,7 +138,7 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			colIndex:    8,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 		{
 			name:        "ConferenceRecord.Wins",
@@ -109,7 +152,7 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			colIndex:    12,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 		{
 			name:        "ConferenceRecord.Losses",
@@ -123,7 +166,7 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			colIndex:    13,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 		{
 			name:        "DivisionRecord.Wins",
@@ -139,12 +182,19 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			expectedT:   &TeamStanding{},
 			expectedErr: nil,
 		},
+		{
+			name:        "DivisionRecord.Wins TBD",
+			colIndex:    17,
+			val:         interface{}("TBD"),
+			expectedT:   &TeamStanding{},
+			expectedErr: nil,
+		},
 		{
 			name:        "DivisionRecord.Wins not a number",
 			colIndex:    17,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 		{
 			name:        "DivisionRecord.Losses",
@@ -165,7 +215,7 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 			colIndex:    18,
 			val:         interface{}("abc"),
 			expectedT:   &TeamStanding{},
-			expectedErr: errors.New(`strconv.Atoi: parsing "abc": invalid syntax`),
+			expectedErr: errors.New(`not a number: "abc"`),
 		},
 	}
 
@@ -182,7 +232,14 @@ func Test_setTeamStandingValBasedOnColumn(t *testing.T) {
 }
 
 func Test_rowToTeamStanding(t *testing.T) {
-	standing, _ := rowToTeamStanding([]interface{}{"tier", "franchise", "name", "conf"})
+	full := func(cells ...interface{}) []interface{} {
+		row := []interface{}{"tier", "franchise", "name", "conf", "", "", "", float64(5), float64(3), "", "", "", float64(2), float64(1)}
+		copy(row, cells)
+		return row
+	}
+
+	standing, err := rowToTeamStanding(full())
+	require.NoError(t, err)
 	require.Equal(t, TeamStanding{
 		Team: models.Team{
 			Tier:       "tier",
@@ -190,16 +247,110 @@ func Test_rowToTeamStanding(t *testing.T) {
 			Name:       "name",
 			Conference: "conf",
 		},
+		OverallRecord:    Record{Wins: 5, Losses: 3},
+		ConferenceRecord: Record{Wins: 2, Losses: 1},
 	}, standing)
 
-	_, err := rowToTeamStanding([]interface{}{"tier", "franchise", "name", "conf", "", "", "", "abc"})
-	require.EqualError(t, err, `strconv.Atoi: parsing "abc": invalid syntax`)
+	tests := []struct {
+		name        string
+		row         []interface{}
+		expectedErr string
+	}{
+		{
+			name:        "trailing blank cells trimmed",
+			row:         []interface{}{"tier", "franchise", "name", "conf"},
+			expectedErr: "row has 4 columns, expected at least 14",
+		},
+		{
+			name:        "missing name",
+			row:         full("tier", "franchise", " "),
+			expectedErr: `missing team: " "`,
+		},
+		{
+			name:        "sentinel wins",
+			row:         full("tier", "franchise", "name", "conf", "", "", "", "N/A"),
+			expectedErr: `missing wins: "N/A"`,
+		},
+		{
+			name:        "not a number",
+			row:         full("tier", "franchise", "name", "conf", "", "", "", "abc"),
+			expectedErr: `not a number: "abc"`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := rowToTeamStanding(test.row)
+		require.EqualErrorf(t, err, test.expectedErr, "%q wrong error", test.name)
+	}
+}
+
+func Test_valuesToTeamStandings_blankRows(t *testing.T) {
+	values := [][]interface{}{
+		{"header"},
+		{"header"},
+		{"tier", "franchise", "name", "conf", "", "", "", float64(5), float64(3), "", "", "", float64(2), float64(1)},
+		{},
+		{"", ""},
+		{"tier", "franchise", "short"},
+	}
+	standings := valuesToTeamStandings("All Teams Data", values)
+	require.Len(t, standings, 1)
+	require.Equal(t, "name", standings[0].Team.Name)
+}
+
+func intPtr(i int) *int {
+	return &i
 }
 
-func Test_assertToString(t *testing.T) {
-	s, _ := assertToString(interface{}("abc"))
-	require.Equal(t, "abc", s)
+func Test_TeamStandings_sheetFixture(t *testing.T) {
+	sheet, err := NewSnapshotSheet(filepath.Join("testdata", "all_teams_data.csv"))
+	require.NoError(t, err)
 
-	_, err := assertToString(interface{}(1))
-	require.EqualError(t, err, "can't convert to string: 1")
+	standings, err := sheet.GetTeamStandingsFromSheet()
+	require.NoError(t, err)
+	require.Equal(t, []TeamStanding{
+		{
+			Team:             models.Team{Tier: "Elite", Franchise: "Rockets Inc", Name: "Rockets", Conference: "Solar", Division: stringPtr("Blue")},
+			Seed:             intPtr(1),
+			GamesBehind:      0,
+			OverallRecord:    Record{Wins: 30, Losses: 6},
+			WinPercentage:    0.833,
+			GameDifferential: 24,
+			Streak:           "W5",
+			ConferenceRecord: Record{Wins: 20, Losses: 4},
+			SeriesRecord:     Record{Wins: 9, Losses: 1},
+			DivisionRecord:   &Record{Wins: 12, Losses: 2},
+		},
+		{
+			Team:             models.Team{Tier: "Elite", Franchise: "Comets Co", Name: "Comets", Conference: "Solar", Division: stringPtr("Blue")},
+			Seed:             intPtr(4),
+			GamesBehind:      7.5,
+			OverallRecord:    Record{Wins: 19, Losses: 14},
+			WinPercentage:    0.576,
+			GameDifferential: 5,
+			Streak:           "L2",
+			ConferenceRecord: Record{Wins: 12, Losses: 12},
+			SeriesRecord:     Record{Wins: 5, Losses: 5},
+			DivisionRecord:   &Record{Wins: 6, Losses: 6},
+		},
+		{
+			Team:             models.Team{Tier: "Elite", Franchise: "Meteor", Name: "Meteors", Conference: "Lunar"},
+			GamesBehind:      3,
+			OverallRecord:    Record{Wins: 22, Losses: 14},
+			WinPercentage:    0.611,
+			GameDifferential: 8,
+			Streak:           "W1",
+			ConferenceRecord: Record{Wins: 15, Losses: 9},
+			SeriesRecord:     Record{Wins: 6, Losses: 4},
+		},
+		{
+			Team:             models.Team{Tier: "Major", Franchise: "Sky", Name: "Skyline", Conference: "Lunar"},
+			GamesBehind:      12,
+			OverallRecord:    Record{Wins: 10, Losses: 26},
+			WinPercentage:    0.278,
+			GameDifferential: -16,
+			ConferenceRecord: Record{Wins: 8, Losses: 16},
+			SeriesRecord:     Record{Wins: 2, Losses: 8},
+		},
+	}, standings)
 }
@@ -0,0 +1,178 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// Table is a list flattened into rows with a fixed column order
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]interface{}
+}
+
+// CellString formats a cell as text, nil pointers are left blank
+func CellString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case *string:
+		if val == nil {
+			return ""
+		}
+		return *val
+	case *int:
+		if val == nil {
+			return ""
+		}
+		return fmt.Sprint(*val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// cellValue formats a cell for XLSX, keeping numbers as numbers
+func cellValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *string:
+		if val == nil {
+			return nil
+		}
+		return *val
+	case *int:
+		if val == nil {
+			return nil
+		}
+		return *val
+	default:
+		return val
+	}
+}
+
+// WriteCSV writes the table as CSV with a header row
+func (t Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Header))
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			record[i] = CellString(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteXLSX writes the table as a workbook with one sheet, the header in the first row
+func (t Table) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(t.Header))
+	for i, h := range t.Header {
+		header[i] = h
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for i, row := range t.Rows {
+		cells := make([]interface{}, len(row))
+		for j, cell := range row {
+			cells[j] = cellValue(cell)
+		}
+		axis, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(axis, cells); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+var (
+	teamColumns     = []string{"id", "name", "franchise", "tier", "conference", "division"}
+	standingColumns = []string{
+		"overall_wins", "overall_losses",
+		"conference_wins", "conference_losses",
+		"division_wins", "division_losses",
+		"series_wins", "series_losses",
+		"win_percentage", "game_differential", "games_behind", "streak", "seed",
+	}
+)
+
+// Teams flattens teams into a table. If standings is non nil, their records are added as extra columns.
+func Teams(teams []models.Team, standings []models.Standing) Table {
+	t := Table{Name: "teams", Header: teamColumns}
+	if standings != nil {
+		t.Header = append(append([]string{}, teamColumns...), standingColumns...)
+	}
+
+	standingByID := make(map[string]models.Standing, len(standings))
+	for _, s := range standings {
+		standingByID[s.Team.TeamID] = s
+	}
+
+	for _, team := range teams {
+		row := []interface{}{team.TeamID, team.Name, team.Franchise, team.Tier, team.Conference, team.Division}
+		if standings != nil {
+			row = append(row, standingCells(standingByID, team.TeamID)...)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// Standings flattens standings into a table of their teams with the standings columns
+func Standings(standings []models.Standing) Table {
+	teams := make([]models.Team, len(standings))
+	for i, s := range standings {
+		teams[i] = s.Team
+	}
+	if standings == nil {
+		standings = []models.Standing{}
+	}
+
+	t := Teams(teams, standings)
+	t.Name = "standings"
+	return t
+}
+
+func standingCells(standingByID map[string]models.Standing, teamID string) []interface{} {
+	s, ok := standingByID[teamID]
+	if !ok {
+		return make([]interface{}, len(standingColumns))
+	}
+
+	var divisionWins, divisionLosses *int
+	if s.DivisionRecord != nil {
+		divisionWins, divisionLosses = &s.DivisionRecord.Wins, &s.DivisionRecord.Losses
+	}
+	return []interface{}{
+		s.OverallRecord.Wins, s.OverallRecord.Losses,
+		s.ConferenceRecord.Wins, s.ConferenceRecord.Losses,
+		divisionWins, divisionLosses,
+		s.SeriesRecord.Wins, s.SeriesRecord.Losses,
+		s.WinPercentage, s.GameDifferential, s.GamesBehind, s.Streak, s.Seed,
+	}
+}
@@ -0,0 +1,72 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func strPointer(s string) *string {
+	return &s
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func Test_CellString(t *testing.T) {
+	var nilStr *string
+	var nilInt *int
+
+	tests := []struct {
+		cell     interface{}
+		expected string
+	}{
+		{cell: nil, expected: ""},
+		{cell: "a", expected: "a"},
+		{cell: 5, expected: "5"},
+		{cell: strPointer("b"), expected: "b"},
+		{cell: nilStr, expected: ""},
+		{cell: intPointer(3), expected: "3"},
+		{cell: nilInt, expected: ""},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, CellString(test.cell))
+	}
+}
+
+func Test_Teams_WriteCSV(t *testing.T) {
+	teams := []models.Team{
+		{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D", Division: strPointer("E")},
+		{TeamID: "2", Name: "F, G", Franchise: "B", Conference: "C", Tier: "D"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Teams(teams, nil).WriteCSV(&buf))
+	require.Equal(t, "id,name,franchise,tier,conference,division\n1,A,B,D,C,E\n2,\"F, G\",B,D,C,\n", buf.String())
+}
+
+func Test_Standings(t *testing.T) {
+	team := models.Team{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D"}
+	standings := []models.Standing{
+		{
+			Team: team, OverallRecord: models.Record{Wins: 5, Losses: 1}, ConferenceRecord: models.Record{Wins: 3, Losses: 1},
+			SeriesRecord: models.Record{Wins: 2}, WinPercentage: 0.833, GameDifferential: 4, GamesBehind: 1.5, Streak: "W2", Seed: intPointer(1),
+		},
+	}
+
+	table := Standings(standings)
+	require.Equal(t, "standings", table.Name)
+	require.Len(t, table.Header, 19)
+
+	var buf bytes.Buffer
+	require.NoError(t, table.WriteCSV(&buf))
+	require.Equal(t, "id,name,franchise,tier,conference,division,overall_wins,overall_losses,conference_wins,conference_losses,division_wins,division_losses,"+
+		"series_wins,series_losses,win_percentage,game_differential,games_behind,streak,seed\n"+
+		"1,A,B,D,C,,5,1,3,1,,,2,0,0.833,4,1.5,W2,1\n", buf.String())
+
+	require.Len(t, Standings(nil).Header, 19)
+}
@@ -3,10 +3,15 @@ module github.com/mellena1/RSC-Spreadsheet-API
 go 1.15
 
 require (
-	github.com/gorilla/handlers v1.4.2
+	github.com/360EntSecGroup-Skylar/excelize/v2 v2.3.2
+	github.com/andybalholm/brotli v1.0.1
+	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.7.4
+	github.com/graph-gophers/graphql-go v1.3.0
 	github.com/lib/pq v1.8.0
+	github.com/prometheus/client_golang v1.7.1
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 	google.golang.org/api v0.30.0
 )
@@ -0,0 +1,390 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+)
+
+// ErrTooComplex is returned once a query would resolve more objects than its complexity limit
+var ErrTooComplex = errors.New("query is too complex")
+
+// Store is where the resolvers get their data
+type Store interface {
+	GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error)
+	GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error)
+	GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error)
+}
+
+// NewSchema parses the schema with resolvers backed by store, rejecting queries nested deeper than maxDepth
+func NewSchema(store Store, maxDepth int) (*graphql.Schema, error) {
+	return graphql.ParseSchema(Schema, &Resolver{store: store}, graphql.MaxDepth(maxDepth))
+}
+
+type budgetCtxKey struct{}
+
+// WithComplexityLimit limits how many objects a query run with ctx can resolve
+func WithComplexityLimit(ctx context.Context, limit int64) context.Context {
+	remaining := limit
+	return context.WithValue(ctx, budgetCtxKey{}, &remaining)
+}
+
+// charge takes n objects from the query's complexity budget
+func charge(ctx context.Context, n int) error {
+	remaining, ok := ctx.Value(budgetCtxKey{}).(*int64)
+	if !ok {
+		return nil
+	}
+	if atomic.AddInt64(remaining, -int64(n)) < 0 {
+		return ErrTooComplex
+	}
+	return nil
+}
+
+// checkBudget is called before loading objects whose count isn't known yet,
+// so nothing is queried once the budget is used up
+func checkBudget(ctx context.Context) error {
+	remaining, ok := ctx.Value(budgetCtxKey{}).(*int64)
+	if !ok {
+		return nil
+	}
+	if atomic.LoadInt64(remaining) < 1 {
+		return ErrTooComplex
+	}
+	return nil
+}
+
+func optionalStrings(vals *[]string) []string {
+	if vals == nil {
+		return nil
+	}
+	return *vals
+}
+
+// Resolver is the root query resolver
+type Resolver struct {
+	store Store
+}
+
+type teamsArgs struct {
+	IDs         *[]graphql.ID
+	Names       *[]string
+	Franchises  *[]string
+	Conferences *[]string
+	Tiers       *[]string
+	Divisions   *[]string
+}
+
+func (r *Resolver) getTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]*teamResolver, error) {
+	if err := checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	teams, err := r.store.GetAllTeams(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, len(teams)); err != nil {
+		return nil, err
+	}
+	return newTeamResolvers(r.store, teams), nil
+}
+
+// Teams resolves teams matching the filters, the same as the /team route
+func (r *Resolver) Teams(ctx context.Context, args teamsArgs) ([]*teamResolver, error) {
+	query := db.GetAllTeamsQuery{
+		Names:       optionalStrings(args.Names),
+		Franchises:  optionalStrings(args.Franchises),
+		Conferences: optionalStrings(args.Conferences),
+		Tiers:       optionalStrings(args.Tiers),
+		Divisions:   optionalStrings(args.Divisions),
+	}
+	if args.IDs != nil {
+		for _, id := range *args.IDs {
+			query.TeamIDs = append(query.TeamIDs, string(id))
+		}
+	}
+	return r.getTeams(ctx, query)
+}
+
+// Team resolves a single team by id
+func (r *Resolver) Team(ctx context.Context, args struct{ ID graphql.ID }) (*teamResolver, error) {
+	teams, err := r.getTeams(ctx, db.GetAllTeamsQuery{TeamIDs: []string{string(args.ID)}})
+	if err != nil || len(teams) == 0 {
+		return nil, err
+	}
+	return teams[0], nil
+}
+
+// Franchises resolves every franchise, or the ones with the given names
+func (r *Resolver) Franchises(ctx context.Context, args struct{ Names *[]string }) ([]*franchiseResolver, error) {
+	teams, err := r.getTeams(ctx, db.GetAllTeamsQuery{Franchises: optionalStrings(args.Names)})
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]*franchiseResolver{}
+	franchises := []*franchiseResolver{}
+	for _, t := range teams {
+		f, ok := byName[t.team.Franchise]
+		if !ok {
+			f = &franchiseResolver{name: t.team.Franchise}
+			byName[f.name] = f
+			franchises = append(franchises, f)
+		}
+		f.teams = append(f.teams, t)
+	}
+	sort.Slice(franchises, func(i, j int) bool { return franchises[i].name < franchises[j].name })
+
+	return franchises, nil
+}
+
+// Franchise resolves a single franchise by name
+func (r *Resolver) Franchise(ctx context.Context, args struct{ Name string }) (*franchiseResolver, error) {
+	franchises, err := r.Franchises(ctx, struct{ Names *[]string }{Names: &[]string{args.Name}})
+	if err != nil || len(franchises) == 0 {
+		return nil, err
+	}
+	return franchises[0], nil
+}
+
+type franchiseResolver struct {
+	name  string
+	teams []*teamResolver
+}
+
+func (f *franchiseResolver) Name() string {
+	return f.name
+}
+
+func (f *franchiseResolver) Teams(args struct{ Tiers *[]string }) []*teamResolver {
+	if args.Tiers == nil {
+		return f.teams
+	}
+	teams := []*teamResolver{}
+	for _, t := range f.teams {
+		for _, tier := range *args.Tiers {
+			if t.team.Tier == tier {
+				teams = append(teams, t)
+				break
+			}
+		}
+	}
+	return teams
+}
+
+// teamBatch loads the standings and players of a list of teams with one query each,
+// the first time any team in the list asks for them. Every team has at most one standing, so they're
+// charged to the complexity budget before they're loaded.
+type teamBatch struct {
+	store   Store
+	teamIDs []string
+
+	standingsOnce sync.Once
+	standings     map[string]models.Standing
+	standingsErr  error
+
+	playersOnce sync.Once
+	players     map[string][]models.Player
+	playersErr  error
+}
+
+func newTeamResolvers(store Store, teams []models.Team) []*teamResolver {
+	batch := &teamBatch{store: store, teamIDs: make([]string, len(teams))}
+	resolvers := make([]*teamResolver, len(teams))
+	for i, t := range teams {
+		batch.teamIDs[i] = t.TeamID
+		resolvers[i] = &teamResolver{team: t, batch: batch}
+	}
+	return resolvers
+}
+
+func (b *teamBatch) getStanding(ctx context.Context, teamID string) (*models.Standing, error) {
+	b.standingsOnce.Do(func() {
+		var standings []models.Standing
+		if b.standingsErr = charge(ctx, len(b.teamIDs)); b.standingsErr == nil {
+			standings, b.standingsErr = b.store.GetStandingsByTeamIDs(ctx, b.teamIDs)
+		}
+		b.standings = make(map[string]models.Standing, len(standings))
+		for _, s := range standings {
+			b.standings[s.Team.TeamID] = s
+		}
+	})
+	if b.standingsErr != nil {
+		return nil, b.standingsErr
+	}
+	s, ok := b.standings[teamID]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (b *teamBatch) getPlayers(ctx context.Context, teamID string) ([]models.Player, error) {
+	b.playersOnce.Do(func() {
+		var players []models.Player
+		if b.playersErr = checkBudget(ctx); b.playersErr == nil {
+			players, b.playersErr = b.store.GetPlayersByTeamIDs(ctx, b.teamIDs)
+		}
+		if b.playersErr == nil {
+			b.playersErr = charge(ctx, len(players))
+		}
+		b.players = map[string][]models.Player{}
+		for _, p := range players {
+			b.players[p.TeamID] = append(b.players[p.TeamID], p)
+		}
+	})
+	return b.players[teamID], b.playersErr
+}
+
+type teamResolver struct {
+	team  models.Team
+	batch *teamBatch
+}
+
+func (t *teamResolver) ID() graphql.ID {
+	return graphql.ID(t.team.TeamID)
+}
+
+func (t *teamResolver) Name() string {
+	return t.team.Name
+}
+
+func (t *teamResolver) Franchise() string {
+	return t.team.Franchise
+}
+
+func (t *teamResolver) Tier() string {
+	return t.team.Tier
+}
+
+func (t *teamResolver) Conference() string {
+	return t.team.Conference
+}
+
+func (t *teamResolver) Division() *string {
+	return t.team.Division
+}
+
+func (t *teamResolver) Standing(ctx context.Context) (*standingResolver, error) {
+	s, err := t.batch.getStanding(ctx, t.team.TeamID)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return &standingResolver{standing: *s}, nil
+}
+
+func (t *teamResolver) Players(ctx context.Context) ([]*playerResolver, error) {
+	players, err := t.batch.getPlayers(ctx, t.team.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*playerResolver, len(players))
+	for i, p := range players {
+		resolvers[i] = &playerResolver{player: p}
+	}
+	return resolvers, nil
+}
+
+type standingResolver struct {
+	standing models.Standing
+}
+
+func (s *standingResolver) OverallRecord() *recordResolver {
+	return &recordResolver{record: s.standing.OverallRecord}
+}
+
+func (s *standingResolver) ConferenceRecord() *recordResolver {
+	return &recordResolver{record: s.standing.ConferenceRecord}
+}
+
+func (s *standingResolver) DivisionRecord() *recordResolver {
+	if s.standing.DivisionRecord == nil {
+		return nil
+	}
+	return &recordResolver{record: *s.standing.DivisionRecord}
+}
+
+func (s *standingResolver) SeriesRecord() *recordResolver {
+	return &recordResolver{record: s.standing.SeriesRecord}
+}
+
+func (s *standingResolver) WinPercentage() float64 {
+	return s.standing.WinPercentage
+}
+
+func (s *standingResolver) GameDifferential() int32 {
+	return int32(s.standing.GameDifferential)
+}
+
+func (s *standingResolver) GamesBehind() float64 {
+	return s.standing.GamesBehind
+}
+
+func (s *standingResolver) Streak() *string {
+	if s.standing.Streak == "" {
+		return nil
+	}
+	return &s.standing.Streak
+}
+
+func (s *standingResolver) Seed() *int32 {
+	if s.standing.Seed == nil {
+		return nil
+	}
+	seed := int32(*s.standing.Seed)
+	return &seed
+}
+
+type recordResolver struct {
+	record models.Record
+}
+
+func (r *recordResolver) Wins() int32 {
+	return int32(r.record.Wins)
+}
+
+func (r *recordResolver) Losses() int32 {
+	return int32(r.record.Losses)
+}
+
+type playerResolver struct {
+	player models.Player
+}
+
+func (p *playerResolver) RscID() graphql.ID {
+	return graphql.ID(p.player.RSCID)
+}
+
+func (p *playerResolver) Name() string {
+	return p.player.Name
+}
+
+func (p *playerResolver) Stats() *statsResolver {
+	return &statsResolver{stats: p.player.Stats}
+}
+
+type statsResolver struct {
+	stats models.Stats
+}
+
+func (s *statsResolver) Goals() int32 {
+	return int32(s.stats.Goals)
+}
+
+func (s *statsResolver) Assists() int32 {
+	return int32(s.stats.Assists)
+}
+
+func (s *statsResolver) Saves() int32 {
+	return int32(s.stats.Saves)
+}
+
+func (s *statsResolver) Shots() int32 {
+	return int32(s.stats.Shots)
+}
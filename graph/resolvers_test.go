@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type storeMock struct {
+	mu    sync.Mutex
+	calls map[string]int
+
+	teams     []models.Team
+	standings []models.Standing
+	players   []models.Player
+
+	lastQuery db.GetAllTeamsQuery
+}
+
+func (s *storeMock) called(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = map[string]int{}
+	}
+	s.calls[method]++
+}
+
+func (s *storeMock) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
+	s.called("GetAllTeams")
+	s.lastQuery = query
+	return s.teams, nil
+}
+
+func (s *storeMock) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	s.called("GetStandingsByTeamIDs")
+	return s.standings, nil
+}
+
+func (s *storeMock) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	s.called("GetPlayersByTeamIDs")
+	return s.players, nil
+}
+
+func strPointer(s string) *string {
+	return &s
+}
+
+func newStoreMock() *storeMock {
+	seed := 1
+	bears := models.Team{TeamID: "1", Name: "Care Bears", Franchise: "Bear Den", Tier: "Master", Conference: "Solar", Division: strPointer("Mountain")}
+	cubs := models.Team{TeamID: "2", Name: "Cubs", Franchise: "Bear Den", Tier: "Elite", Conference: "Lunar"}
+	hawks := models.Team{TeamID: "3", Name: "Hawks", Franchise: "Aviary", Tier: "Master", Conference: "Solar"}
+
+	return &storeMock{
+		teams: []models.Team{bears, cubs, hawks},
+		standings: []models.Standing{
+			{Team: bears, OverallRecord: models.Record{Wins: 5, Losses: 1}, DivisionRecord: &models.Record{Wins: 2},
+				SeriesRecord: models.Record{Wins: 2}, WinPercentage: 0.833, GameDifferential: 4, Streak: "W3", Seed: &seed},
+			{Team: cubs, OverallRecord: models.Record{Wins: 1, Losses: 5}},
+		},
+		players: []models.Player{
+			{RSCID: "RSC001", Name: "Teddy", TeamID: "1", Stats: models.Stats{Goals: 10}},
+			{RSCID: "RSC002", Name: "Paddington", TeamID: "1"},
+		},
+	}
+}
+
+func Test_Schema_Franchises(t *testing.T) {
+	store := newStoreMock()
+	schema, err := NewSchema(store, 10)
+	require.NoError(t, err)
+
+	resp := schema.Exec(context.Background(), `{
+		franchises {
+			name
+			teams(tiers: ["Master"]) {
+				id
+				name
+				division
+				standing {
+					overallRecord { wins losses } divisionRecord { wins } seriesRecord { wins losses }
+					winPercentage gameDifferential gamesBehind streak seed
+				}
+				players { rscId name stats { goals } }
+			}
+		}
+	}`, "", nil)
+	require.Empty(t, resp.Errors)
+
+	expected := `{"franchises":[
+		{"name":"Aviary","teams":[{"id":"3","name":"Hawks","division":null,"standing":null,"players":[]}]},
+		{"name":"Bear Den","teams":[{"id":"1","name":"Care Bears","division":"Mountain",
+			"standing":{"overallRecord":{"wins":5,"losses":1},"divisionRecord":{"wins":2},"seriesRecord":{"wins":2,"losses":0},
+				"winPercentage":0.833,"gameDifferential":4,"gamesBehind":0,"streak":"W3","seed":1},
+			"players":[{"rscId":"RSC001","name":"Teddy","stats":{"goals":10}},{"rscId":"RSC002","name":"Paddington","stats":{"goals":0}}]}]}
+	]}`
+	require.JSONEq(t, expected, string(resp.Data))
+
+	// each level is loaded with one query no matter how many teams there are
+	require.Equal(t, map[string]int{"GetAllTeams": 1, "GetStandingsByTeamIDs": 1, "GetPlayersByTeamIDs": 1}, store.calls)
+}
+
+func Test_Schema_TeamsFilters(t *testing.T) {
+	store := newStoreMock()
+	schema, err := NewSchema(store, 10)
+	require.NoError(t, err)
+
+	resp := schema.Exec(context.Background(), `{ teams(ids: ["1", "2"], tiers: ["Master"], conferences: ["Solar"]) { id } }`, "", nil)
+	require.Empty(t, resp.Errors)
+
+	require.Equal(t, db.GetAllTeamsQuery{
+		TeamIDs:     []string{"1", "2"},
+		Tiers:       []string{"Master"},
+		Conferences: []string{"Solar"},
+	}, store.lastQuery)
+}
+
+func Test_Schema_Team(t *testing.T) {
+	store := newStoreMock()
+	store.teams = store.teams[:1]
+	schema, err := NewSchema(store, 10)
+	require.NoError(t, err)
+
+	resp := schema.Exec(context.Background(), `{ team(id: "1") { name } }`, "", nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"team":{"name":"Care Bears"}}`, string(resp.Data))
+
+	store.teams = nil
+	resp = schema.Exec(context.Background(), `{ team(id: "1") { name } }`, "", nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"team":null}`, string(resp.Data))
+}
+
+func Test_Schema_MaxDepth(t *testing.T) {
+	schema, err := NewSchema(newStoreMock(), 3)
+	require.NoError(t, err)
+
+	resp := schema.Exec(context.Background(), `{ franchises { teams { standing { overallRecord { wins } } } } }`, "", nil)
+	require.NotEmpty(t, resp.Errors)
+}
+
+func Test_Schema_ComplexityLimit(t *testing.T) {
+	schema, err := NewSchema(newStoreMock(), 10)
+	require.NoError(t, err)
+
+	// 3 teams, then 1 standing for each of them
+	ctx := WithComplexityLimit(context.Background(), 5)
+	resp := schema.Exec(ctx, `{ teams { id standing { overallRecord { wins } } } }`, "", nil)
+	require.NotEmpty(t, resp.Errors)
+	require.Equal(t, ErrTooComplex.Error(), resp.Errors[0].Message)
+
+	ctx = WithComplexityLimit(context.Background(), 6)
+	resp = schema.Exec(ctx, `{ teams { id standing { overallRecord { wins } } } }`, "", nil)
+	require.Empty(t, resp.Errors)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+}
+
+func Test_Schema_ComplexityLimit_checkedBeforeLoading(t *testing.T) {
+	store := newStoreMock()
+	schema, err := NewSchema(store, 10)
+	require.NoError(t, err)
+
+	ctx := WithComplexityLimit(context.Background(), 3)
+	resp := schema.Exec(ctx, `{ teams { id standing { seed } players { name } } }`, "", nil)
+	require.NotEmpty(t, resp.Errors)
+	require.Equal(t, 1, store.calls["GetAllTeams"])
+	require.Equal(t, 0, store.calls["GetStandingsByTeamIDs"])
+	require.Equal(t, 0, store.calls["GetPlayersByTeamIDs"])
+}
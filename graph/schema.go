@@ -0,0 +1,61 @@
+package graph
+
+// Schema is the GraphQL schema served by the API
+const Schema = `
+schema {
+	query: Query
+}
+
+type Query {
+	teams(ids: [ID!], names: [String!], franchises: [String!], conferences: [String!], tiers: [String!], divisions: [String!]): [Team!]!
+	team(id: ID!): Team
+	franchises(names: [String!]): [Franchise!]!
+	franchise(name: String!): Franchise
+}
+
+type Franchise {
+	name: String!
+	teams(tiers: [String!]): [Team!]!
+}
+
+type Team {
+	id: ID!
+	name: String!
+	franchise: String!
+	tier: String!
+	conference: String!
+	division: String
+	standing: Standing
+	players: [Player!]!
+}
+
+type Standing {
+	overallRecord: Record!
+	conferenceRecord: Record!
+	divisionRecord: Record
+	seriesRecord: Record!
+	winPercentage: Float!
+	gameDifferential: Int!
+	gamesBehind: Float!
+	streak: String
+	seed: Int
+}
+
+type Record {
+	wins: Int!
+	losses: Int!
+}
+
+type Player {
+	rscId: ID!
+	name: String!
+	stats: Stats!
+}
+
+type Stats {
+	goals: Int!
+	assists: Int!
+	saves: Int!
+	shots: Int!
+}
+`
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSyncRunsLimit = 20
+	maxSyncRunsLimit     = 100
+)
+
+// CacheClearer is a cache that can be emptied
+type CacheClearer interface {
+	Clear()
+}
+
+// AdminHandler has all routes for operating the API
+type AdminHandler struct {
+	DB     db.Syncer
+	Caches []CacheClearer
+}
+
+// AddRoutes adds all of it's routes to the router
+func (a *AdminHandler) AddRoutes(router *mux.Router) {
+	if a.DB == nil {
+		log.Fatal("AdminHandler.DB is nil!")
+	}
+
+	router.HandleFunc("/sync", a.sync).Methods("POST")
+	router.HandleFunc("/sync/runs", a.getSyncRuns).Methods("GET")
+	router.HandleFunc("/cache", a.clearCache).Methods("DELETE")
+}
+
+// AdminTokenMiddleware only lets through requests with the bearer token in their Authorization header
+func AdminTokenMiddleware(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				logging.FromContext(r.Context()).Warn("Unauthorized admin request")
+				writeError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type syncRunsResp struct {
+	Runs []models.SyncRun `json:"runs"`
+}
+
+func (a *AdminHandler) sync(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	source := r.URL.Query().Get("source")
+	runs, err := a.DB.Sync(r.Context(), source)
+	if err == db.ErrUnknownSource {
+		log.Warnf("Sync requested for unknown source: %s", source)
+		writeError(w, "Unknown source", http.StatusNotFound)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if err != nil {
+		statusCode = http.StatusBadGateway
+	}
+
+	var body interface{} = &syncRunsResp{Runs: runs}
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newItemEnvelope(r, runs)
+	}
+	if err := writeJSON(w, r, statusCode, body); err != nil {
+		log.Errorf("Unable to marshal sync runs: %s", err)
+		writeError(w, "Error sending sync runs", http.StatusInternalServerError)
+	}
+}
+
+func (a *AdminHandler) getSyncRuns(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	limit := defaultSyncRunsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxSyncRunsLimit {
+			writeError(w, "limit must be an integer from 1 to 100", http.StatusBadRequest)
+			return
+		}
+	}
+
+	runs, err := a.DB.GetSyncRuns(r.Context(), limit)
+	if err != nil {
+		log.Errorf("Unable to fetch sync runs from db: %s", err)
+		writeError(w, "Failed to fetch sync runs from db", http.StatusInternalServerError)
+		return
+	}
+
+	var body interface{} = &syncRunsResp{Runs: runs}
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newListEnvelope(r, runs, limit, len(runs))
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
+		log.Errorf("Unable to marshal sync runs: %s", err)
+		writeError(w, "Error sending sync runs", http.StatusInternalServerError)
+	}
+}
+
+func (a *AdminHandler) clearCache(w http.ResponseWriter, r *http.Request) {
+	for _, c := range a.Caches {
+		c.Clear()
+	}
+	logging.FromContext(r.Context()).Info("Cleared caches")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type syncerMock struct {
+	t *testing.T
+
+	expectedSource string
+	syncRuns       []models.SyncRun
+	syncErr        error
+
+	expectedLimit int
+	runs          []models.SyncRun
+	runsErr       error
+}
+
+func (s syncerMock) Sources() []string {
+	return []string{db.TeamStandingsSource}
+}
+
+func (s syncerMock) Sync(ctx context.Context, source string) ([]models.SyncRun, error) {
+	require.Equal(s.t, s.expectedSource, source)
+	return s.syncRuns, s.syncErr
+}
+
+func (s syncerMock) GetSyncRuns(ctx context.Context, limit int) ([]models.SyncRun, error) {
+	require.Equal(s.t, s.expectedLimit, limit)
+	return s.runs, s.runsErr
+}
+
+type cacheMock struct {
+	cleared *bool
+}
+
+func (c cacheMock) Clear() {
+	*c.cleared = true
+}
+
+func Test_AdminHandler_AddRoutes_NilDB(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	aHandler := AdminHandler{}
+	aHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_AdminTokenMiddleware(t *testing.T) {
+	tests := []struct {
+		name               string
+		token              string
+		authHeader         string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Correct token",
+			token:              "secret",
+			authHeader:         "Bearer secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Wrong token",
+			token:              "secret",
+			authHeader:         "Bearer guess",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "No token",
+			token:              "secret",
+			authHeader:         "",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "Token not configured",
+			token:              "",
+			authHeader:         "Bearer ",
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, test := range tests {
+		handler := AdminTokenMiddleware(test.token)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		req := httptest.NewRequest("POST", "/admin/sync", nil)
+		req.Header.Set("Authorization", test.authHeader)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equalf(t, test.expectedStatusCode, recorder.Code, "%q wrong status code", test.name)
+	}
+}
+
+func Test_AdminHandler(t *testing.T) {
+	startedAt := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	syncErr := "sheets api down"
+	goodRun := models.SyncRun{ID: 2, Source: db.TeamStandingsSource, StartedAt: startedAt, DurationMS: 150, RowsFetched: 10, Added: 1, Unchanged: 9}
+	badRun := models.SyncRun{ID: 1, Source: db.TeamStandingsSource, StartedAt: startedAt, DurationMS: 20, Error: &syncErr}
+	goodRunJSON := `{"id":2,"source":"team_standings","started_at":"2020-09-01T12:00:00Z","duration_ms":150,"rows_fetched":10,"added":1,"changed":0,"removed":0,"unchanged":9}`
+	badRunJSON := `{"id":1,"source":"team_standings","started_at":"2020-09-01T12:00:00Z","duration_ms":20,"rows_fetched":0,"added":0,"changed":0,"removed":0,"unchanged":0,"error":"sheets api down"}`
+
+	tests := []struct {
+		name               string
+		mockDB             syncerMock
+		requestPath        string
+		requestMethod      string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Sync all",
+			mockDB:             syncerMock{syncRuns: []models.SyncRun{goodRun}},
+			requestPath:        "/sync",
+			requestMethod:      "POST",
+			expectedResp:       fmt.Sprintf(`{"runs":[%s]}`, goodRunJSON),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Sync one source",
+			mockDB:             syncerMock{expectedSource: "team_standings", syncRuns: []models.SyncRun{goodRun}},
+			requestPath:        "/sync?source=team_standings",
+			requestMethod:      "POST",
+			expectedResp:       fmt.Sprintf(`{"runs":[%s]}`, goodRunJSON),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Sync unknown source",
+			mockDB:             syncerMock{expectedSource: "abc", syncErr: db.ErrUnknownSource},
+			requestPath:        "/sync?source=abc",
+			requestMethod:      "POST",
+			expectedResp:       `{"error":"Unknown source"}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Sync failed",
+			mockDB:             syncerMock{syncRuns: []models.SyncRun{badRun}, syncErr: errRandom},
+			requestPath:        "/sync",
+			requestMethod:      "POST",
+			expectedResp:       fmt.Sprintf(`{"runs":[%s]}`, badRunJSON),
+			expectedStatusCode: 502,
+		},
+		{
+			name:               "Get runs",
+			mockDB:             syncerMock{expectedLimit: 20, runs: []models.SyncRun{goodRun, badRun}},
+			requestPath:        "/sync/runs",
+			requestMethod:      "GET",
+			expectedResp:       fmt.Sprintf(`{"runs":[%s,%s]}`, goodRunJSON, badRunJSON),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Get runs with limit",
+			mockDB:             syncerMock{expectedLimit: 1, runs: []models.SyncRun{goodRun}},
+			requestPath:        "/sync/runs?limit=1",
+			requestMethod:      "GET",
+			expectedResp:       fmt.Sprintf(`{"runs":[%s]}`, goodRunJSON),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Get runs bad limit",
+			mockDB:             syncerMock{},
+			requestPath:        "/sync/runs?limit=1000",
+			requestMethod:      "GET",
+			expectedResp:       `{"error":"limit must be an integer from 1 to 100"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Get runs db error",
+			mockDB:             syncerMock{expectedLimit: 20, runsErr: errRandom},
+			requestPath:        "/sync/runs",
+			requestMethod:      "GET",
+			expectedResp:       `{"error":"Failed to fetch sync runs from db"}`,
+			expectedStatusCode: 500,
+		},
+	}
+
+	for _, test := range tests {
+		test.mockDB.t = t
+		aHandler := AdminHandler{DB: test.mockDB}
+		router := mux.NewRouter()
+		aHandler.AddRoutes(router)
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest(test.requestMethod, url, nil)
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
+
+func Test_AdminHandler_clearCache(t *testing.T) {
+	var cleared bool
+	aHandler := AdminHandler{DB: syncerMock{t: t}, Caches: []CacheClearer{cacheMock{cleared: &cleared}}}
+	router := mux.NewRouter()
+	aHandler.AddRoutes(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/cache", nil))
+
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, true, cleared)
+}
+
+func Test_AdminHandler_v1Envelope(t *testing.T) {
+	startedAt := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	run := models.SyncRun{ID: 2, Source: db.TeamStandingsSource, StartedAt: startedAt, DurationMS: 150, RowsFetched: 10, Added: 1, Unchanged: 9}
+	runJSON := `{"id":2,"source":"team_standings","started_at":"2020-09-01T12:00:00Z","duration_ms":150,"rows_fetched":10,"added":1,"changed":0,"removed":0,"unchanged":9}`
+
+	aHandler := AdminHandler{DB: syncerMock{t: t, expectedLimit: 5, runs: []models.SyncRun{run}}}
+	router := mux.NewRouter()
+	v1 := APIVersion{Name: "v1"}
+	subR := router.PathPrefix("/v1/admin").Subrouter()
+	subR.Use(v1.Middleware)
+	aHandler.AddRoutes(subR)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/admin/sync/runs?limit=5", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, fmt.Sprintf(`{"data":[%s],"meta":{"page":{"offset":0,"limit":5,"count":1}},"links":{"self":"/v1/admin/sync/runs?limit=5"}}`, runJSON), recorder.Body.String())
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// APIKeyHandler has all routes for issuing and revoking API keys
+type APIKeyHandler struct {
+	DB db.APIKeyStore
+}
+
+// AddRoutes adds all of it's routes to the router
+func (h *APIKeyHandler) AddRoutes(router *mux.Router) {
+	if h.DB == nil {
+		log.Fatal("APIKeyHandler.DB is nil!")
+	}
+
+	router.HandleFunc("", h.createKey).Methods("POST")
+	router.HandleFunc("/", h.createKey).Methods("POST")
+	router.HandleFunc("/{id}", h.revokeKey).Methods("DELETE")
+}
+
+type createAPIKeyReq struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	// RateLimit is requests per minute, the keyed default is used if it isn't set
+	RateLimit *int `json:"rate_limit,omitempty"`
+}
+
+// createdAPIKey is a new key with the raw key, which is only ever shown once
+type createdAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+func (h *APIKeyHandler) createKey(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	req := createAPIKeyReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.Owner == "" {
+		writeError(w, "name and owner are required", http.StatusBadRequest)
+		return
+	}
+	if req.RateLimit != nil && *req.RateLimit < 1 {
+		writeError(w, "rate_limit must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	key, apiKey, err := h.DB.CreateAPIKey(r.Context(), req.Name, req.Owner, req.RateLimit)
+	if err != nil {
+		log.Errorf("Unable to create api key: %s", err)
+		writeError(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Created API key %d for %s", apiKey.ID, apiKey.Owner)
+
+	created := createdAPIKey{APIKey: apiKey, Key: key}
+	var body interface{} = &created
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newItemEnvelope(r, &created)
+	}
+	if err := writeJSON(w, r, http.StatusCreated, body); err != nil {
+		log.Errorf("Unable to marshal api key: %s", err)
+		writeError(w, "Error sending API key", http.StatusInternalServerError)
+	}
+}
+
+func (h *APIKeyHandler) revokeKey(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "API key ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	err = h.DB.RevokeAPIKey(r.Context(), id)
+	if err == db.ErrAPIKeyNotFound {
+		writeError(w, "API key not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Errorf("Unable to revoke api key: %s", err)
+		writeError(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Revoked API key %d", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
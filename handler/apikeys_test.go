@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyAdminMock struct {
+	db.APIKeyStore
+
+	t *testing.T
+
+	created    models.APIKey
+	rawKey     string
+	expectedID int
+	err        error
+}
+
+func (m apiKeyAdminMock) CreateAPIKey(ctx context.Context, name, owner string, rateLimit *int) (string, models.APIKey, error) {
+	require.Equal(m.t, m.created.Name, name)
+	require.Equal(m.t, m.created.Owner, owner)
+	require.Equal(m.t, m.created.RateLimit, rateLimit)
+	return m.rawKey, m.created, m.err
+}
+
+func (m apiKeyAdminMock) RevokeAPIKey(ctx context.Context, id int) error {
+	require.Equal(m.t, m.expectedID, id)
+	return m.err
+}
+
+func Test_APIKeyHandler_AddRoutes_NilDB(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	kHandler := APIKeyHandler{}
+	kHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_APIKeyHandler(t *testing.T) {
+	createdAt := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	key := models.APIKey{ID: 3, Name: "bot", Owner: "someone", RateLimit: intPointer(600), CreatedAt: createdAt}
+
+	tests := []struct {
+		name               string
+		mockDB             apiKeyAdminMock
+		requestPath        string
+		requestMethod      string
+		requestBody        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Create",
+			mockDB:             apiKeyAdminMock{created: key, rawKey: "abc123"},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"name":"bot","owner":"someone","rate_limit":600}`,
+			expectedResp:       `{"id":3,"name":"bot","owner":"someone","rate_limit":600,"created_at":"2020-09-01T12:00:00Z","key":"abc123"}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "Create without rate limit",
+			mockDB:             apiKeyAdminMock{created: models.APIKey{ID: 4, Name: "bot", Owner: "someone", CreatedAt: createdAt}, rawKey: "def456"},
+			requestPath:        "",
+			requestMethod:      "POST",
+			requestBody:        `{"name":"bot","owner":"someone"}`,
+			expectedResp:       `{"id":4,"name":"bot","owner":"someone","created_at":"2020-09-01T12:00:00Z","key":"def456"}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "Create missing owner",
+			mockDB:             apiKeyAdminMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"name":"bot"}`,
+			expectedResp:       `{"error":"name and owner are required"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Create zero rate limit",
+			mockDB:             apiKeyAdminMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"name":"bot","owner":"someone","rate_limit":0}`,
+			expectedResp:       `{"error":"rate_limit must be a positive integer"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Create bad body",
+			mockDB:             apiKeyAdminMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{`,
+			expectedResp:       `{"error":"Invalid request body"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Create db error",
+			mockDB:             apiKeyAdminMock{created: key, err: errRandom},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"name":"bot","owner":"someone","rate_limit":600}`,
+			expectedResp:       `{"error":"Failed to create API key"}`,
+			expectedStatusCode: 500,
+		},
+		{
+			name:               "Revoke",
+			mockDB:             apiKeyAdminMock{expectedID: 3},
+			requestPath:        "/3",
+			requestMethod:      "DELETE",
+			expectedResp:       "",
+			expectedStatusCode: 204,
+		},
+		{
+			name:               "Revoke not found",
+			mockDB:             apiKeyAdminMock{expectedID: 5, err: db.ErrAPIKeyNotFound},
+			requestPath:        "/5",
+			requestMethod:      "DELETE",
+			expectedResp:       `{"error":"API key not found"}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Revoke bad id",
+			mockDB:             apiKeyAdminMock{},
+			requestPath:        "/abc",
+			requestMethod:      "DELETE",
+			expectedResp:       `{"error":"API key ID must be an integer"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Revoke db error",
+			mockDB:             apiKeyAdminMock{expectedID: 3, err: errRandom},
+			requestPath:        "/3",
+			requestMethod:      "DELETE",
+			expectedResp:       `{"error":"Failed to revoke API key"}`,
+			expectedStatusCode: 500,
+		},
+	}
+
+	for _, test := range tests {
+		test.mockDB.t = t
+		kHandler := APIKeyHandler{DB: test.mockDB}
+		router := mux.NewRouter()
+		kHandler.AddRoutes(router)
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest(test.requestMethod, url, strings.NewReader(test.requestBody))
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+)
+
+// APIKeyHeader is the header clients can send their api key in
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyQueryParam is the query param clients can send their api key in
+const APIKeyQueryParam = "api_key"
+
+type apiKeyCtxKey struct{}
+
+// APIKeyFromContext gets the api key a request authenticated with
+func APIKeyFromContext(ctx context.Context) (models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(models.APIKey)
+	return key, ok
+}
+
+// Authenticator checks api keys and rate limits requests.
+// Requests without a key are limited by ip address at the anonymous limit, unless RequireKey is set.
+// Behind a load balancer set TrustedProxies, otherwise every client shares the balancer's address and one bucket.
+type Authenticator struct {
+	Keys       db.APIKeyStore
+	RequireKey bool
+
+	// AnonymousLimit is the number of requests per minute for requests without a key
+	AnonymousLimit int
+	// KeyLimit is the number of requests per minute for keys without their own limit
+	KeyLimit int
+	// InvalidKeyLimit is the number of invalid keys an ip address can send per minute. Once it's used up
+	// requests with a key get a 429 without the key being looked up, so guessing keys can't flood the db.
+	InvalidKeyLimit int
+	// TrustedProxies are the networks of proxies whose X-Forwarded-For header is trusted to find the client's ip address
+	TrustedProxies []*net.IPNet
+
+	limiter *rateLimiter
+}
+
+// defaultInvalidKeyLimit is how many invalid keys an ip address can send per minute by default
+const defaultInvalidKeyLimit = 10
+
+// NewAuthenticator makes an Authenticator
+func NewAuthenticator(keys db.APIKeyStore, requireKey bool, anonymousLimit, keyLimit int) *Authenticator {
+	return &Authenticator{
+		Keys:           keys,
+		RequireKey:     requireKey,
+		AnonymousLimit: anonymousLimit,
+		KeyLimit:       keyLimit,
+		// InvalidKeyLimit is separate from the anonymous limit so blocking anonymous requests doesn't block keys
+		InvalidKeyLimit: defaultInvalidKeyLimit,
+		limiter:         newRateLimiter(),
+	}
+}
+
+func getAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(APIKeyQueryParam)
+}
+
+// ParseTrustedProxies parses a list of CIDRs like "10.0.0.0/8", a bare ip address is a network of just that address
+func ParseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %v", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (a *Authenticator) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range a.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP is the address the request came from. If that's a trusted proxy it's the last address in
+// X-Forwarded-For that isn't one, earlier ones are sent by the client and can be anything.
+func (a *Authenticator) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !a.trusted(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !a.trusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// Middleware authenticates and rate limits requests before passing them on
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logging.FromContext(r.Context())
+
+		ip := a.clientIP(r)
+		bucketID := "ip:" + ip
+		limit := a.AnonymousLimit
+
+		if rawKey := getAPIKey(r); rawKey != "" {
+			invalidID := "invalid:" + ip
+			if result := a.limiter.check(invalidID, a.InvalidKeyLimit); !result.Allowed {
+				log.Warnf("Rate limited %s", invalidID)
+				writeRateLimited(w, result, "Too many invalid API keys")
+				return
+			}
+
+			apiKey, err := a.Keys.GetAPIKey(r.Context(), rawKey)
+			if err == db.ErrAPIKeyNotFound || (err == nil && apiKey.Revoked()) {
+				log.Warn("Request with an invalid api key")
+				a.limiter.allow(invalidID, a.InvalidKeyLimit)
+				writeError(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			} else if err != nil {
+				log.Errorf("Unable to fetch api key from db: %s", err)
+				writeError(w, "Failed to check API key", http.StatusInternalServerError)
+				return
+			}
+
+			bucketID = "key:" + strconv.Itoa(apiKey.ID)
+			limit = a.KeyLimit
+			if apiKey.RateLimit != nil {
+				limit = *apiKey.RateLimit
+			}
+
+			log = log.WithField("api_key", apiKey.Name)
+			ctx := logging.WithLogger(r.Context(), log)
+			ctx = context.WithValue(ctx, apiKeyCtxKey{}, apiKey)
+			r = r.WithContext(ctx)
+		} else if a.RequireKey {
+			writeError(w, "An API key is required", http.StatusUnauthorized)
+			return
+		}
+
+		result := a.limiter.allow(bucketID, limit)
+		if !result.Allowed {
+			log.Warnf("Rate limited %s", bucketID)
+			writeRateLimited(w, result, "Rate limit exceeded")
+			return
+		}
+		setRateLimitHeaders(w, result)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, result rateLimitResult) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(result.Reset.Seconds())))
+}
+
+// writeRateLimited sends a 429 for a request that was over a limit
+func writeRateLimited(w http.ResponseWriter, result rateLimitResult, msg string) {
+	setRateLimitHeaders(w, result)
+	if result.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+	}
+	writeError(w, msg, http.StatusTooManyRequests)
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyStoreMock struct {
+	db.APIKeyStore
+
+	keys map[string]models.APIKey
+	err  error
+	// lookups counts the calls to GetAPIKey if set
+	lookups *int
+}
+
+func (a apiKeyStoreMock) GetAPIKey(ctx context.Context, key string) (models.APIKey, error) {
+	if a.lookups != nil {
+		*a.lookups++
+	}
+	if a.err != nil {
+		return models.APIKey{}, a.err
+	}
+	apiKey, ok := a.keys[key]
+	if !ok {
+		return models.APIKey{}, db.ErrAPIKeyNotFound
+	}
+	return apiKey, nil
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func Test_Authenticator_Middleware(t *testing.T) {
+	revokedAt := time.Now()
+	keys := apiKeyStoreMock{keys: map[string]models.APIKey{
+		"good":    {ID: 1, Name: "bot"},
+		"limited": {ID: 2, Name: "limited bot", RateLimit: intPointer(1)},
+		"revoked": {ID: 3, Name: "old bot", RevokedAt: &revokedAt},
+	}}
+
+	tests := []struct {
+		name               string
+		keys               db.APIKeyStore
+		requireKey         bool
+		headerKey          string
+		queryKey           string
+		requests           int
+		expectedStatusCode int
+		expectedResp       string
+		expectedLimit      string
+		expectedKeyName    string
+	}{
+		{
+			name:               "Anonymous",
+			keys:               keys,
+			requests:           1,
+			expectedStatusCode: 200,
+			expectedLimit:      "2",
+		},
+		{
+			name:               "Anonymous rate limited",
+			keys:               keys,
+			requests:           3,
+			expectedStatusCode: 429,
+			expectedResp:       `{"error":"Rate limit exceeded"}`,
+			expectedLimit:      "2",
+		},
+		{
+			name:               "Anonymous with key required",
+			keys:               keys,
+			requireKey:         true,
+			requests:           1,
+			expectedStatusCode: 401,
+			expectedResp:       `{"error":"An API key is required"}`,
+		},
+		{
+			name:               "Key in header",
+			keys:               keys,
+			headerKey:          "good",
+			requests:           3,
+			expectedStatusCode: 200,
+			expectedLimit:      "5",
+			expectedKeyName:    "bot",
+		},
+		{
+			name:               "Key in query",
+			keys:               keys,
+			queryKey:           "good",
+			requests:           1,
+			expectedStatusCode: 200,
+			expectedLimit:      "5",
+			expectedKeyName:    "bot",
+		},
+		{
+			name:               "Key with own limit",
+			keys:               keys,
+			headerKey:          "limited",
+			requests:           2,
+			expectedStatusCode: 429,
+			expectedResp:       `{"error":"Rate limit exceeded"}`,
+			expectedLimit:      "1",
+			expectedKeyName:    "limited bot",
+		},
+		{
+			name:               "Unknown key",
+			keys:               keys,
+			headerKey:          "bad",
+			requests:           1,
+			expectedStatusCode: 401,
+			expectedResp:       `{"error":"Invalid API key"}`,
+		},
+		{
+			name:               "Revoked key",
+			keys:               keys,
+			headerKey:          "revoked",
+			requests:           1,
+			expectedStatusCode: 401,
+			expectedResp:       `{"error":"Invalid API key"}`,
+		},
+		{
+			name:               "DB error",
+			keys:               apiKeyStoreMock{err: errRandom},
+			headerKey:          "good",
+			requests:           1,
+			expectedStatusCode: 500,
+			expectedResp:       `{"error":"Failed to check API key"}`,
+		},
+	}
+
+	for _, test := range tests {
+		var keyName string
+		authenticator := NewAuthenticator(test.keys, test.requireKey, 2, 5)
+		handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, _ := APIKeyFromContext(r.Context())
+			keyName = key.Name
+		}))
+
+		var recorder *httptest.ResponseRecorder
+		for i := 0; i < test.requests; i++ {
+			req := httptest.NewRequest("GET", "/team", nil)
+			if test.headerKey != "" {
+				req.Header.Set(APIKeyHeader, test.headerKey)
+			}
+			if test.queryKey != "" {
+				req.URL.RawQuery = APIKeyQueryParam + "=" + test.queryKey
+			}
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+		}
+
+		result := recorder.Result()
+		require.Equalf(t, test.expectedStatusCode, result.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(result.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+		require.Equalf(t, test.expectedLimit, result.Header.Get("X-RateLimit-Limit"), "%q wrong limit", test.name)
+		require.Equalf(t, test.expectedKeyName, keyName, "%q wrong key in context", test.name)
+	}
+}
+
+func Test_Authenticator_clientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(nil, false, 2, 5)
+	authenticator.TrustedProxies = proxies
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expectedIP   string
+	}{
+		{
+			name:       "No proxy",
+			remoteAddr: "203.0.113.5:1234",
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:         "Forwarded by untrusted client",
+			remoteAddr:   "203.0.113.5:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			expectedIP:   "203.0.113.5",
+		},
+		{
+			name:         "Forwarded by trusted proxy",
+			remoteAddr:   "10.1.2.3:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			expectedIP:   "198.51.100.7",
+		},
+		{
+			name:         "Spoofed hop before the client",
+			remoteAddr:   "10.1.2.3:1234",
+			forwardedFor: []string{"1.1.1.1, 198.51.100.7", "192.168.1.1"},
+			expectedIP:   "198.51.100.7",
+		},
+		{
+			name:         "Invalid hop",
+			remoteAddr:   "10.1.2.3:1234",
+			forwardedFor: []string{"garbage"},
+			expectedIP:   "10.1.2.3",
+		},
+		{
+			name:       "Trusted proxy without header",
+			remoteAddr: "10.1.2.3:1234",
+			expectedIP: "10.1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/team", nil)
+		req.RemoteAddr = test.remoteAddr
+		for _, v := range test.forwardedFor {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		require.Equalf(t, test.expectedIP, authenticator.clientIP(req), "%q wrong ip", test.name)
+	}
+
+	_, err = ParseTrustedProxies([]string{"nope"})
+	require.Error(t, err)
+}
+
+func Test_Authenticator_Middleware_zeroLimit(t *testing.T) {
+	authenticator := NewAuthenticator(apiKeyStoreMock{}, false, 0, 5)
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/team", nil))
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", recorder.Header().Get("X-RateLimit-Reset"))
+	require.Empty(t, recorder.Header().Get("Retry-After"))
+}
+
+func Test_Authenticator_Middleware_invalidKeyLimit(t *testing.T) {
+	lookups := 0
+	keys := apiKeyStoreMock{keys: map[string]models.APIKey{"good": {ID: 1, Name: "bot"}}, lookups: &lookups}
+	authenticator := NewAuthenticator(keys, false, 2, 5)
+	authenticator.InvalidKeyLimit = 2
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(key, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/team", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(APIKeyHeader, key)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// valid keys don't use up the invalid key limit
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, send("good", "203.0.113.5:1234").Code)
+	}
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusUnauthorized, send("guess", "203.0.113.5:1234").Code)
+	}
+	require.Equal(t, 5, lookups)
+
+	// once the limit is used up keys from that address aren't looked up
+	recorder := send("guess", "203.0.113.5:1234")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, `{"error":"Too many invalid API keys"}`, recorder.Body.String())
+	require.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusTooManyRequests, send("good", "203.0.113.5:1234").Code)
+	require.Equal(t, 5, lookups)
+
+	// other addresses have their own limit
+	require.Equal(t, http.StatusOK, send("good", "198.51.100.7:1234").Code)
+	require.Equal(t, 6, lookups)
+}
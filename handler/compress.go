@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressibleTypes are the content types worth compressing, others like xlsx are already compressed
+var compressibleTypes = []string{"application/json", "text/csv", "text/html", "text/plain"}
+
+// negotiateEncoding picks brotli or gzip from an Accept-Encoding header by q-value, preferring brotli on ties.
+// It returns "" if the client accepts neither.
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "br" && coding != "gzip" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > 0 && (q > bestQ || (q == bestQ && coding == "br")) {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func compressible(contentType string) bool {
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter holds off on writing headers until the first write,
+// so it can decide from the content type whether to compress the body
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	code    int
+	started bool
+	encoder io.WriteCloser
+}
+
+func (c *compressWriter) WriteHeader(code int) {
+	if c.started {
+		return
+	}
+	c.code = code
+}
+
+// start writes the headers, compressing the body if it's worth it
+func (c *compressWriter) start(firstWrite []byte) {
+	c.started = true
+	h := c.Header()
+
+	// sniff the type now like net/http would, since it can't sniff a compressed body
+	if h.Get("Content-Type") == "" && len(firstWrite) > 0 {
+		h.Set("Content-Type", http.DetectContentType(firstWrite))
+	}
+
+	noBody := c.code == http.StatusNoContent || c.code == http.StatusNotModified
+	if !noBody && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", c.encoding)
+		h.Del("Content-Length")
+		if c.encoding == "br" {
+			c.encoder = brotli.NewWriterLevel(c.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			c.encoder = gzip.NewWriter(c.ResponseWriter)
+		}
+	}
+
+	c.ResponseWriter.WriteHeader(c.code)
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if !c.started {
+		c.start(b)
+	}
+	if c.encoder != nil {
+		return c.encoder.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the compressor
+func (c *compressWriter) Flush() {
+	if !c.started {
+		c.start(nil)
+	}
+	if f, ok := c.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *compressWriter) close() error {
+	if !c.started {
+		c.start(nil)
+	}
+	if c.encoder != nil {
+		return c.encoder.Close()
+	}
+	return nil
+}
+
+// Compress compresses JSON and text responses with brotli or gzip, whichever the client prefers
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, code: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_negotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "", expected: ""},
+		{acceptEncoding: "gzip", expected: "gzip"},
+		{acceptEncoding: "gzip, deflate, br", expected: "br"},
+		{acceptEncoding: "br;q=0.5, gzip", expected: "gzip"},
+		{acceptEncoding: "br;q=0, gzip;q=0", expected: ""},
+		{acceptEncoding: "deflate, identity", expected: ""},
+		{acceptEncoding: "GZIP;q=0.8", expected: "gzip"},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, negotiateEncoding(test.acceptEncoding), "wrong encoding for %q", test.acceptEncoding)
+	}
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	var decoded []byte
+	var err error
+	switch encoding {
+	case "gzip":
+		r, gzErr := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, gzErr)
+		decoded, err = ioutil.ReadAll(r)
+	case "br":
+		decoded, err = ioutil.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+	default:
+		decoded = body
+	}
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func Test_Compress(t *testing.T) {
+	body := `{"teams":[` + strings.Repeat(`{"name":"Care Bears"},`, 50) + `{}]}`
+
+	tests := []struct {
+		name             string
+		method           string
+		acceptEncoding   string
+		contentType      string
+		statusCode       int
+		body             string
+		expectedEncoding string
+		expectedType     string
+	}{
+		{
+			name:             "Gzip JSON",
+			method:           "GET",
+			acceptEncoding:   "gzip",
+			statusCode:       200,
+			body:             body,
+			expectedEncoding: "gzip",
+			expectedType:     "text/plain; charset=utf-8",
+		},
+		{
+			name:             "Brotli JSON",
+			method:           "GET",
+			acceptEncoding:   "gzip, br",
+			contentType:      "application/json",
+			statusCode:       200,
+			body:             body,
+			expectedEncoding: "br",
+			expectedType:     "application/json",
+		},
+		{
+			name:             "Errors are compressed too",
+			method:           "GET",
+			acceptEncoding:   "gzip",
+			statusCode:       500,
+			body:             `{"error":"Failed to fetch teams from db"}`,
+			expectedEncoding: "gzip",
+			expectedType:     "text/plain; charset=utf-8",
+		},
+		{
+			name:             "Not accepted",
+			method:           "GET",
+			acceptEncoding:   "deflate",
+			statusCode:       200,
+			body:             body,
+			expectedEncoding: "",
+			expectedType:     "",
+		},
+		{
+			name:             "Already compressed type",
+			method:           "GET",
+			acceptEncoding:   "gzip",
+			contentType:      xlsxContentType,
+			statusCode:       200,
+			body:             body,
+			expectedEncoding: "",
+			expectedType:     xlsxContentType,
+		},
+		{
+			name:             "Event streams aren't compressed",
+			method:           "GET",
+			acceptEncoding:   "gzip",
+			contentType:      "text/event-stream",
+			statusCode:       200,
+			body:             "id: 1\nevent: team.added\ndata: {}\n\n",
+			expectedEncoding: "",
+			expectedType:     "text/event-stream",
+		},
+		{
+			name:             "Not modified",
+			method:           "GET",
+			acceptEncoding:   "gzip",
+			statusCode:       304,
+			expectedEncoding: "",
+		},
+	}
+
+	for _, test := range tests {
+		handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if test.contentType != "" {
+				w.Header().Set("Content-Type", test.contentType)
+			}
+			w.WriteHeader(test.statusCode)
+			w.Write([]byte(test.body))
+		}))
+
+		req := httptest.NewRequest(test.method, "/team", nil)
+		req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		require.Equalf(t, test.statusCode, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, test.expectedEncoding, recorder.Header().Get("Content-Encoding"), "%q wrong encoding", test.name)
+		require.Equalf(t, test.expectedType, recorder.Header().Get("Content-Type"), "%q wrong content type", test.name)
+		require.Equalf(t, "Accept-Encoding", recorder.Header().Get("Vary"), "%q wrong vary", test.name)
+		require.Equalf(t, test.body, decompress(t, test.expectedEncoding, recorder.Body.Bytes()), "%q wrong body", test.name)
+		if test.expectedEncoding != "" && test.body == body {
+			require.Lessf(t, recorder.Body.Len(), len(test.body), "%q body should be smaller", test.name)
+		}
+	}
+}
+
+func Test_Compress_flush(t *testing.T) {
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"a":`))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`1}`))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, true, recorder.Flushed)
+	require.Equal(t, `{"a":1}`, decompress(t, "gzip", recorder.Body.Bytes()))
+}
@@ -0,0 +1,149 @@
+package handler
+
+// docsViewerScript renders the OpenAPI document on the docs page when the Redoc bundle hasn't been
+// generated into redoc_bundle.go. It only shows the parts of the spec this API uses.
+const docsViewerScript = `(function () {
+  "use strict";
+
+  var root = document.getElementById("docs");
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) node.className = className;
+    if (text !== undefined && text !== null) node.textContent = text;
+    return node;
+  }
+
+  function refName(ref) {
+    return ref.split("/").pop();
+  }
+
+  // describe writes a schema as an indented outline, refs are expanded once per branch so cycles end
+  function describe(spec, schema, seen, depth) {
+    if (!schema) return "";
+    var pad = new Array(depth + 1).join("  ");
+    if (schema.$ref) {
+      var name = refName(schema.$ref);
+      if (seen.indexOf(name) >= 0) return name;
+      return describe(spec, spec.components.schemas[name], seen.concat([name]), depth);
+    }
+    var type = schema.type || "any";
+    if (schema.format) type += " (" + schema.format + ")";
+    if (schema.enum) type += " one of " + schema.enum.join(", ");
+    if (schema.nullable) type += ", nullable";
+    if (schema.type === "array") {
+      return "array of " + describe(spec, schema.items, seen, depth);
+    }
+    if (schema.properties) {
+      var required = schema.required || [];
+      var lines = Object.keys(schema.properties).sort().map(function (prop) {
+        var mark = required.indexOf(prop) >= 0 ? "" : "?";
+        return pad + "  " + prop + mark + ": " + describe(spec, schema.properties[prop], seen, depth + 1);
+      });
+      return "{\n" + lines.join("\n") + "\n" + pad + "}";
+    }
+    if (schema.additionalProperties) {
+      return "map of " + describe(spec, schema.additionalProperties, seen, depth);
+    }
+    return type;
+  }
+
+  function content(spec, media) {
+    var block = el("div");
+    Object.keys(media || {}).forEach(function (type) {
+      block.appendChild(el("div", "media", type));
+      block.appendChild(el("pre", "schema", describe(spec, media[type].schema, [], 0)));
+    });
+    return block;
+  }
+
+  function operation(spec, method, path, op) {
+    var section = el("details", "op" + (op.deprecated ? " deprecated" : ""));
+    var summary = el("summary");
+    summary.appendChild(el("span", "method " + method, method.toUpperCase()));
+    summary.appendChild(el("code", "path", path));
+    summary.appendChild(el("span", "summary", op.summary));
+    section.appendChild(summary);
+
+    if (op.deprecated) section.appendChild(el("p", "note", "Deprecated"));
+    if (op.description) section.appendChild(el("p", null, op.description));
+    if (op.security) {
+      var schemes = op.security.map(function (s) { return Object.keys(s).join(" + "); });
+      section.appendChild(el("p", "note", "Auth: " + schemes.join(" or ")));
+    }
+
+    if (op.parameters && op.parameters.length) {
+      section.appendChild(el("h4", null, "Parameters"));
+      var table = el("table");
+      op.parameters.forEach(function (p) {
+        var row = el("tr");
+        row.appendChild(el("td", null, p.name + (p.required ? "" : "?")));
+        row.appendChild(el("td", null, p.in));
+        row.appendChild(el("td", null, describe(spec, p.schema, [], 0)));
+        row.appendChild(el("td", null, p.description));
+        table.appendChild(row);
+      });
+      section.appendChild(table);
+    }
+
+    if (op.requestBody) {
+      section.appendChild(el("h4", null, "Request body"));
+      section.appendChild(content(spec, op.requestBody.content));
+    }
+
+    section.appendChild(el("h4", null, "Responses"));
+    Object.keys(op.responses || {}).sort().forEach(function (code) {
+      var resp = op.responses[code];
+      section.appendChild(el("div", "status", code + " " + resp.description));
+      section.appendChild(content(spec, resp.content));
+    });
+    return section;
+  }
+
+  var methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+
+  function render(spec) {
+    document.title = spec.info.title;
+    root.appendChild(el("h1", null, spec.info.title + " " + spec.info.version));
+    if (spec.info.description) root.appendChild(el("p", null, spec.info.description));
+
+    var byTag = {};
+    Object.keys(spec.paths).sort().forEach(function (path) {
+      var item = spec.paths[path];
+      // path items can also have parameters, servers and descriptions, only the methods are operations
+      methods.filter(function (method) { return item[method]; }).forEach(function (method) {
+        var op = Object.assign({}, item[method], {
+          parameters: (item.parameters || []).concat(item[method].parameters || [])
+        });
+        var tag = (op.tags && op.tags[0]) || "other";
+        (byTag[tag] = byTag[tag] || []).push(operation(spec, method, path, op));
+      });
+    });
+    Object.keys(byTag).sort().forEach(function (tag) {
+      root.appendChild(el("h2", null, tag));
+      byTag[tag].forEach(function (op) { root.appendChild(op); });
+    });
+  }
+
+  fetch(root.getAttribute("data-spec-url"))
+    .then(function (resp) { return resp.json(); })
+    .then(render)
+    .catch(function (err) { root.appendChild(el("p", "note", "Unable to load the API spec: " + err)); });
+})();
+`
+
+// docsViewerStyle styles the page rendered by docsViewerScript
+const docsViewerStyle = `body { font-family: sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; color: #222; }
+h2 { border-bottom: 1px solid #ddd; text-transform: capitalize; }
+.op { border: 1px solid #ddd; border-radius: 4px; margin: .5em 0; padding: .5em; }
+.op summary { cursor: pointer; }
+.deprecated .path { text-decoration: line-through; }
+.method { display: inline-block; width: 4.5em; font-weight: bold; }
+.get { color: #2b7a0b; } .post { color: #1d5fa8; } .delete { color: #b3261e; }
+.path { margin-right: 1em; }
+.summary, .note { color: #555; }
+.schema { background: #f6f8fa; padding: .5em; overflow-x: auto; }
+.media, .status { font-weight: bold; margin-top: .5em; }
+table { border-collapse: collapse; }
+td { border: 1px solid #ddd; padding: .25em .5em; vertical-align: top; }
+`
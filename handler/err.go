@@ -12,6 +12,7 @@ type errorResp struct {
 
 func writeError(w http.ResponseWriter, errorMsg string, statuscode int) {
 	msg, _ := json.Marshal(&errorResp{Error: errorMsg})
+	w.Header().Set("Content-Type", jsonContentType)
 	w.WriteHeader(statuscode)
 	w.Write(msg)
 }
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/events"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// eventReplayLimit is how many missed events are fetched from the db at a time when resuming
+	eventReplayLimit  = 500
+	heartbeatInterval = 30 * time.Second
+)
+
+// EventsHandler streams changes found by syncs as Server-Sent Events
+type EventsHandler struct {
+	DB     db.EventStore
+	Broker *events.Broker
+}
+
+// AddRoutes adds all of it's routes to the router
+func (h *EventsHandler) AddRoutes(router *mux.Router) {
+	if h.DB == nil {
+		log.Fatal("EventsHandler.DB is nil!")
+	}
+	if h.Broker == nil {
+		log.Fatal("EventsHandler.Broker is nil!")
+	}
+
+	router.HandleFunc("", h.streamEvents).Methods("GET")
+	router.HandleFunc("/", h.streamEvents).Methods("GET")
+}
+
+// eventFilter only lets through events for the given tiers, franchises and teams
+type eventFilter struct {
+	Tiers      []string
+	Franchises []string
+	TeamIDs    []string
+}
+
+func matchesAny(vals []string, s string) bool {
+	if len(vals) == 0 {
+		return true
+	}
+	for _, v := range vals {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (f eventFilter) matches(event models.Event) bool {
+	team := event.Team()
+	return matchesAny(f.Tiers, team.Tier) &&
+		matchesAny(f.Franchises, team.Franchise) &&
+		matchesAny(f.TeamIDs, team.TeamID)
+}
+
+// writeSSE writes an event in the text/event-stream format
+func writeSSE(w http.ResponseWriter, event models.Event) error {
+	data, err := json.Marshal(&event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func getLastEventID(r *http.Request) (int64, error) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(lastEventID, 10, 64)
+}
+
+func (h *EventsHandler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Error("ResponseWriter doesn't support flushing")
+		writeError(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Errorf("Invalid URL query string: %s", err)
+		writeError(w, "Invalid query", http.StatusBadRequest)
+		return
+	}
+	filter := eventFilter{
+		Tiers:      r.Form["tier"],
+		Franchises: r.Form["franchise"],
+		TeamIDs:    r.Form["team"],
+	}
+
+	lastID, err := getLastEventID(r)
+	if err != nil {
+		writeError(w, "Last-Event-ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	// subscribe before replaying so nothing published during the replay is missed
+	live, unsubscribe := h.Broker.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(batch []models.Event) error {
+		for _, event := range batch {
+			if event.ID <= lastID {
+				continue
+			}
+			lastID = event.ID
+			if !filter.matches(event) {
+				continue
+			}
+			if err := writeSSE(w, event); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	if lastID > 0 {
+		for {
+			missed, err := h.DB.GetEventsSince(r.Context(), lastID, eventReplayLimit)
+			if err != nil {
+				log.Errorf("Unable to fetch events from db: %s", err)
+				return
+			}
+			if err := send(missed); err != nil {
+				return
+			}
+			if len(missed) < eventReplayLimit {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case batch, open := <-live:
+			if !open {
+				// fell too far behind, the client will reconnect and resume with Last-Event-ID
+				log.Warn("Dropped slow event stream subscriber")
+				return
+			}
+			if err := send(batch); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/events"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type eventStoreMock struct {
+	t *testing.T
+
+	expectedLastID int64
+	events         []models.Event
+}
+
+func (e eventStoreMock) GetEventsSince(ctx context.Context, lastID int64, limit int) ([]models.Event, error) {
+	require.Equal(e.t, e.expectedLastID, lastID)
+	return e.events, nil
+}
+
+func makeEvent(id int64, eventType models.EventType, teamID, tier string) models.Event {
+	return models.Event{
+		ID:        id,
+		Type:      eventType,
+		Current:   &models.Standing{Team: models.Team{TeamID: teamID, Tier: tier}},
+		CreatedAt: time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func Test_eventFilter_matches(t *testing.T) {
+	event := models.Event{Current: &models.Standing{Team: models.Team{TeamID: "1", Tier: "Master", Franchise: "Bears"}}}
+
+	require.Equal(t, true, eventFilter{}.matches(event))
+	require.Equal(t, true, eventFilter{Tiers: []string{"Elite", "Master"}, Franchises: []string{"Bears"}}.matches(event))
+	require.Equal(t, false, eventFilter{Tiers: []string{"Master"}, TeamIDs: []string{"2"}}.matches(event))
+}
+
+func Test_getLastEventID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/events?last_event_id=4", nil)
+	id, err := getLastEventID(req)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), id)
+
+	req.Header.Set("Last-Event-ID", "7")
+	id, err = getLastEventID(req)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), id)
+
+	id, err = getLastEventID(httptest.NewRequest("GET", "/events", nil))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), id)
+
+	req.Header.Set("Last-Event-ID", "abc")
+	_, err = getLastEventID(req)
+	require.Error(t, err)
+}
+
+func Test_EventsHandler_streamEvents(t *testing.T) {
+	broker := events.NewBroker()
+	store := eventStoreMock{
+		t:              t,
+		expectedLastID: 3,
+		events: []models.Event{
+			makeEvent(4, models.EventTeamAdded, "1", "Master"),
+			makeEvent(5, models.EventTeamAdded, "2", "Elite"),
+		},
+	}
+	eHandler := EventsHandler{DB: store, Broker: broker}
+	router := mux.NewRouter()
+	eHandler.AddRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/?tier=Master", nil)
+	req.Header.Set("Last-Event-ID", "3")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		lines := []string{}
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	require.Equal(t, "id: 4\nevent: team.added\n"+
+		`data: {"id":4,"type":"team.added","current":{"team":{"id":"1","name":"","franchise":"","tier":"Master","conference":""},"overall_record":{"wins":0,"losses":0},"conference_record":{"wins":0,"losses":0},"series_record":{"wins":0,"losses":0},"win_percentage":0,"game_differential":0,"games_behind":0},"created_at":"2020-09-01T12:00:00Z"}`+"\n",
+		readEvent())
+
+	// already replayed and filtered out events are skipped
+	broker.Publish([]models.Event{
+		makeEvent(5, models.EventTeamAdded, "2", "Master"),
+		makeEvent(6, models.EventTeamRemoved, "3", "Elite"),
+		makeEvent(7, models.EventStandingRecordChanged, "1", "Master"),
+	})
+	require.True(t, strings.HasPrefix(readEvent(), "id: 7\nevent: standing.record_changed\n"))
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/tabular"
+)
+
+// formats list endpoints can be exported as
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// formatQueryParam overrides the Accept header when choosing a format
+const formatQueryParam = "format"
+
+var errUnknownFormat = errors.New("unknown format")
+
+// negotiateFormat picks the response format from the format query param, or else the Accept header by q-value,
+// preferring the type listed first on ties. JSON is used unless a spreadsheet format is preferred.
+func negotiateFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get(formatQueryParam)); format != "" {
+		switch format {
+		case formatJSON, formatCSV, formatXLSX:
+			return format, nil
+		default:
+			return "", errUnknownFormat
+		}
+	}
+
+	best, bestQ := formatJSON, 0.0
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		var format string
+		switch mediaType {
+		case csvContentType:
+			format = formatCSV
+		case xlsxContentType:
+			format = formatXLSX
+		case "application/json":
+			format = formatJSON
+		default:
+			continue
+		}
+
+		q := 1.0
+		if param, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(param, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best, nil
+}
+
+func setAttachment(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+}
+
+// writeTable writes t to w in a spreadsheet format
+func writeTable(w http.ResponseWriter, format string, t tabular.Table) error {
+	switch format {
+	case formatCSV:
+		setAttachment(w, csvContentType+"; charset=utf-8", t.Name+".csv")
+		return t.WriteCSV(w)
+	case formatXLSX:
+		setAttachment(w, xlsxContentType, t.Name+".xlsx")
+		return t.WriteXLSX(w)
+	default:
+		return errUnknownFormat
+	}
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type standingsMock struct {
+	resp []models.Standing
+	err  error
+}
+
+func (s standingsMock) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	return s.resp, s.err
+}
+
+func (s standingsMock) GetAllStandings(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Standing, error) {
+	return s.resp, s.err
+}
+
+func Test_negotiateFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestPath string
+		accept      string
+		expected    string
+		expectedErr error
+	}{
+		{name: "Default", requestPath: "/team", expected: formatJSON},
+		{name: "Browser accept", requestPath: "/team", accept: "text/html,application/xhtml+xml,*/*;q=0.8", expected: formatJSON},
+		{name: "CSV accept", requestPath: "/team", accept: "text/csv", expected: formatCSV},
+		{name: "XLSX accept", requestPath: "/team", accept: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", expected: formatXLSX},
+		{name: "Lower q CSV", requestPath: "/team", accept: "text/csv;q=0.1, application/json", expected: formatJSON},
+		{name: "Higher q CSV", requestPath: "/team", accept: "application/json;q=0.5, text/csv", expected: formatCSV},
+		{name: "Refused CSV", requestPath: "/team", accept: "text/csv;q=0", expected: formatJSON},
+		{name: "Ties go to the first type", requestPath: "/team", accept: "text/csv, application/json", expected: formatCSV},
+		{name: "Format param", requestPath: "/team?format=xlsx", expected: formatXLSX},
+		{name: "Format param beats accept", requestPath: "/team?format=CSV", accept: "application/json", expected: formatCSV},
+		{name: "Unknown format", requestPath: "/team?format=pdf", expectedErr: errUnknownFormat},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.requestPath, nil)
+		req.Header.Set("Accept", test.accept)
+		actual, err := negotiateFormat(req)
+		require.Equalf(t, test.expectedErr, err, "%q wrong error", test.name)
+		require.Equalf(t, test.expected, actual, "%q wrong format", test.name)
+	}
+}
+
+func Test_getAllTeams_export(t *testing.T) {
+	teams := []models.Team{
+		{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D", Division: strPointer("E")},
+		{TeamID: "2", Name: "F, G", Franchise: "B", Conference: "C", Tier: "D"},
+	}
+	standings := []models.Standing{
+		{Team: teams[0], OverallRecord: models.Record{Wins: 5, Losses: 1}, ConferenceRecord: models.Record{Wins: 3, Losses: 1}, DivisionRecord: &models.Record{Wins: 2, Losses: 0}},
+	}
+
+	tests := []struct {
+		name               string
+		standings          db.StandingsStore
+		requestPath        string
+		accept             string
+		expectedResp       string
+		expectedType       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "CSV without standings",
+			requestPath:        "/?format=csv",
+			expectedResp:       "id,name,franchise,tier,conference,division\n1,A,B,D,C,E\n2,\"F, G\",B,D,C,\n",
+			expectedType:       "text/csv; charset=utf-8",
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "CSV with standings",
+			standings:   standingsMock{resp: standings},
+			requestPath: "/",
+			accept:      "text/csv",
+			expectedResp: "id,name,franchise,tier,conference,division,overall_wins,overall_losses,conference_wins,conference_losses,division_wins,division_losses," +
+				"series_wins,series_losses,win_percentage,game_differential,games_behind,streak,seed\n" +
+				"1,A,B,D,C,E,5,1,3,1,2,0,0,0,0,0,0,,\n" +
+				"2,\"F, G\",B,D,C,,,,,,,,,,,,,,\n",
+			expectedType:       "text/csv; charset=utf-8",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Standings error",
+			standings:          standingsMock{err: errRandom},
+			requestPath:        "/?format=csv",
+			expectedResp:       `{"error":"Failed to fetch standings from db"}`,
+			expectedStatusCode: 500,
+		},
+		{
+			name:               "Unknown format",
+			requestPath:        "/?format=pdf",
+			expectedResp:       `{"error":"format must be one of json, csv or xlsx"}`,
+			expectedStatusCode: 400,
+		},
+	}
+
+	for _, test := range tests {
+		tHandler := TeamHandler{DB: getAllTeamsMockDB{t: t, resp: teams}, Standings: test.standings}
+		router := mux.NewRouter()
+		tHandler.AddRoutes(router)
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Accept", test.accept)
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		require.Equalf(t, "Accept", actual.Header.Get("Vary"), "%q wrong vary", test.name)
+		if test.expectedType != "" {
+			require.Equalf(t, test.expectedType, actual.Header.Get("Content-Type"), "%q wrong content type", test.name)
+		}
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
+
+func Test_getAllTeams_exportXLSX(t *testing.T) {
+	teams := []models.Team{
+		{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D", Division: strPointer("E")},
+		{TeamID: "2", Name: "F", Franchise: "B", Conference: "C", Tier: "D"},
+	}
+	standings := []models.Standing{
+		{Team: teams[0], OverallRecord: models.Record{Wins: 5, Losses: 1}, ConferenceRecord: models.Record{Wins: 3, Losses: 1}},
+	}
+
+	tHandler := TeamHandler{DB: getAllTeamsMockDB{t: t, resp: teams}, Standings: standingsMock{resp: standings}}
+	router := mux.NewRouter()
+	tHandler.AddRoutes(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/?format=xlsx", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, xlsxContentType, recorder.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="teams.xlsx"`, recorder.Header().Get("Content-Disposition"))
+
+	f, err := excelize.OpenReader(bytes.NewReader(recorder.Body.Bytes()))
+	require.NoError(t, err)
+	rows, err := f.GetRows(f.GetSheetName(0))
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"id", "name", "franchise", "tier", "conference", "division", "overall_wins", "overall_losses", "conference_wins", "conference_losses", "division_wins", "division_losses",
+			"series_wins", "series_losses", "win_percentage", "game_differential", "games_behind", "streak", "seed"},
+		{"1", "A", "B", "D", "C", "E", "5", "1", "3", "1", "", "", "0", "0", "0", "0", "0", "", ""},
+		{"2", "F", "B", "D", "C", "", "", "", "", "", "", "", "", "", "", "", "", "", ""},
+	}, rows)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/mellena1/RSC-Spreadsheet-API/graph"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// GraphQLHandler serves queries against the graph schema
+type GraphQLHandler struct {
+	Schema *graphql.Schema
+	// MaxComplexity is how many objects a single query may resolve, 0 means no limit
+	MaxComplexity int64
+}
+
+// graphQLRequest is the standard body of a GraphQL request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// AddRoutes adds all of it's routes to the router
+func (h *GraphQLHandler) AddRoutes(router *mux.Router) {
+	if h.Schema == nil {
+		log.Fatal("GraphQLHandler.Schema is nil!")
+	}
+
+	router.HandleFunc("", h.query).Methods("GET", "POST")
+	router.HandleFunc("/", h.query).Methods("GET", "POST")
+}
+
+// parseGraphQLRequest reads the query from the body of a POST, or the query params of a GET
+func parseGraphQLRequest(r *http.Request) (graphQLRequest, error) {
+	var req graphQLRequest
+	if r.Method == http.MethodPost {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		return req, err
+	}
+
+	params := r.URL.Query()
+	req.Query = params.Get("query")
+	req.OperationName = params.Get("operationName")
+	if variables := params.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+func (h *GraphQLHandler) query(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	req, err := parseGraphQLRequest(r)
+	if err != nil {
+		writeError(w, "Invalid GraphQL request", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		writeError(w, "Missing query", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if h.MaxComplexity > 0 {
+		ctx = graph.WithComplexityLimit(ctx, h.MaxComplexity)
+	}
+
+	resp := h.Schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if len(resp.Errors) > 0 {
+		log.Debugf("GraphQL query returned errors: %v", resp.Errors)
+	}
+
+	if err := writeJSON(w, r, http.StatusOK, resp); err != nil {
+		log.Errorf("Error marshalling GraphQL response: %v", err)
+		writeError(w, "Failed to marshal response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/graph"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type graphStoreMock struct {
+	teams []models.Team
+}
+
+func (g graphStoreMock) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
+	return g.teams, nil
+}
+
+func (g graphStoreMock) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	return nil, nil
+}
+
+func (g graphStoreMock) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	return nil, nil
+}
+
+func Test_GraphQLHandler_AddRoutes_NilSchema(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	gHandler := GraphQLHandler{}
+	gHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_GraphQLHandler(t *testing.T) {
+	store := graphStoreMock{teams: []models.Team{
+		{TeamID: "1", Name: "Care Bears", Franchise: "Bear Den", Tier: "Master", Conference: "Solar"},
+		{TeamID: "2", Name: "Cubs", Franchise: "Bear Den", Tier: "Elite", Conference: "Lunar"},
+	}}
+	schema, err := graph.NewSchema(store, 5)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		maxComplexity      int64
+		requestMethod      string
+		requestPath        string
+		requestBody        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "POST query",
+			requestMethod:      "POST",
+			requestPath:        "",
+			requestBody:        `{"query":"{ teams { name } }"}`,
+			expectedResp:       `{"data":{"teams":[{"name":"Care Bears"},{"name":"Cubs"}]}}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "POST query with variables",
+			requestMethod:      "POST",
+			requestPath:        "/",
+			requestBody:        `{"query":"query T($id: ID!) { team(id: $id) { name } }","variables":{"id":"1"}}`,
+			expectedResp:       `{"data":{"team":{"name":"Care Bears"}}}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "GET query",
+			requestMethod:      "GET",
+			requestPath:        "?query=" + url.QueryEscape("{ franchises { name } }"),
+			expectedResp:       `{"data":{"franchises":[{"name":"Bear Den"}]}}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Too complex",
+			maxComplexity:      1,
+			requestMethod:      "POST",
+			requestBody:        `{"query":"{ teams { name } }"}`,
+			expectedResp:       `{"errors":[{"message":"query is too complex","path":["teams"]}],"data":null}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Bad body",
+			requestMethod:      "POST",
+			requestBody:        `{"query":`,
+			expectedResp:       `{"error":"Invalid GraphQL request"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Missing query",
+			requestMethod:      "GET",
+			expectedResp:       `{"error":"Missing query"}`,
+			expectedStatusCode: 400,
+		},
+	}
+
+	for _, test := range tests {
+		gHandler := GraphQLHandler{Schema: schema, MaxComplexity: test.maxComplexity}
+		router := mux.NewRouter()
+		gHandler.AddRoutes(router.PathPrefix("/graphql").Subrouter())
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s/graphql%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest(test.requestMethod, url, strings.NewReader(test.requestBody))
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.JSONEqf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
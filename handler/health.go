@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// HealthHandler has all routes for checking the health of the API
+type HealthHandler struct {
+	DB      db.StatusReporter
+	Version string
+}
+
+// AddRoutes adds all of it's routes to the router
+func (h *HealthHandler) AddRoutes(router *mux.Router) {
+	if h.DB == nil {
+		log.Fatal("HealthHandler.DB is nil!")
+	}
+
+	router.HandleFunc("/healthz", h.healthz).Methods("GET")
+	router.HandleFunc("/readyz", h.readyz).Methods("GET")
+	router.HandleFunc("/status", h.status).Methods("GET")
+}
+
+type healthResp struct {
+	Status string `json:"status"`
+}
+
+type statusResp struct {
+	LastSync    *time.Time     `json:"last_sync"`
+	RowCounts   map[string]int `json:"row_counts"`
+	DataSources []string       `json:"data_sources"`
+	Version     string         `json:"version"`
+}
+
+func (h *HealthHandler) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, &healthResp{Status: "ok"})
+}
+
+func (h *HealthHandler) readyz(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	if err := h.DB.Ping(); err != nil {
+		log.Errorf("Unable to ping db: %s", err)
+		writeError(w, "Database is unreachable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if h.DB.LastSync().IsZero() {
+		writeError(w, "Data has not been synced yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &healthResp{Status: "ready"})
+}
+
+func (h *HealthHandler) status(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	counts, err := h.DB.TableRowCounts()
+	if err != nil {
+		log.Errorf("Unable to count rows in db: %s", err)
+		writeError(w, "Failed to fetch status from db", http.StatusInternalServerError)
+		return
+	}
+
+	resp := statusResp{
+		RowCounts:   counts,
+		DataSources: h.DB.DataSources(),
+		Version:     h.Version,
+	}
+	if lastSync := h.DB.LastSync(); !lastSync.IsZero() {
+		resp.LastSync = &lastSync
+	}
+
+	if err := writeJSON(w, r, http.StatusOK, &resp); err != nil {
+		log.Errorf("Unable to marshal status: %s", err)
+		writeError(w, "Error sending status", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type statusReporterMock struct {
+	pingErr   error
+	lastSync  time.Time
+	counts    map[string]int
+	countsErr error
+}
+
+func (s statusReporterMock) Ping() error {
+	return s.pingErr
+}
+
+func (s statusReporterMock) LastSync() time.Time {
+	return s.lastSync
+}
+
+func (s statusReporterMock) TableRowCounts() (map[string]int, error) {
+	return s.counts, s.countsErr
+}
+
+func (s statusReporterMock) DataSources() []string {
+	return []string{"sheet1"}
+}
+
+func Test_HealthHandler_AddRoutes(t *testing.T) {
+	router := mux.NewRouter()
+
+	hHandler := HealthHandler{DB: statusReporterMock{}}
+	hHandler.AddRoutes(router)
+
+	tests := []struct {
+		req      *http.Request
+		expected http.HandlerFunc
+	}{
+		{
+			req:      makeReq("GET", "/healthz"),
+			expected: hHandler.healthz,
+		},
+		{
+			req:      makeReq("GET", "/readyz"),
+			expected: hHandler.readyz,
+		},
+		{
+			req:      makeReq("GET", "/status"),
+			expected: hHandler.status,
+		},
+	}
+	for _, test := range tests {
+		routeMatch := &mux.RouteMatch{}
+		matched := router.Match(test.req, routeMatch)
+		require.Equal(t, true, matched)
+		// use sprintf to compare function addresses
+		require.Equal(t, fmt.Sprintf("%v", test.expected), fmt.Sprintf("%v", routeMatch.Handler))
+	}
+}
+
+func Test_HealthHandler_AddRoutes_NilDB(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	hHandler := HealthHandler{}
+	hHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_HealthHandler(t *testing.T) {
+	lastSync := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		mockDB             db.StatusReporter
+		requestPath        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Healthz",
+			mockDB:             statusReporterMock{pingErr: errRandom},
+			requestPath:        "/healthz",
+			expectedResp:       `{"status":"ok"}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Ready",
+			mockDB:             statusReporterMock{lastSync: lastSync},
+			requestPath:        "/readyz",
+			expectedResp:       `{"status":"ready"}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Not ready, db down",
+			mockDB:             statusReporterMock{pingErr: errRandom, lastSync: lastSync},
+			requestPath:        "/readyz",
+			expectedResp:       `{"error":"Database is unreachable"}`,
+			expectedStatusCode: 503,
+		},
+		{
+			name:               "Not ready, never synced",
+			mockDB:             statusReporterMock{},
+			requestPath:        "/readyz",
+			expectedResp:       `{"error":"Data has not been synced yet"}`,
+			expectedStatusCode: 503,
+		},
+		{
+			name:               "Status",
+			mockDB:             statusReporterMock{lastSync: lastSync, counts: map[string]int{"team": 3}},
+			requestPath:        "/status",
+			expectedResp:       `{"last_sync":"2020-09-01T12:00:00Z","row_counts":{"team":3},"data_sources":["sheet1"],"version":"v1.0.0"}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Status never synced",
+			mockDB:             statusReporterMock{counts: map[string]int{"team": 0}},
+			requestPath:        "/status",
+			expectedResp:       `{"last_sync":null,"row_counts":{"team":0},"data_sources":["sheet1"],"version":"v1.0.0"}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Status db error",
+			mockDB:             statusReporterMock{countsErr: errRandom},
+			requestPath:        "/status",
+			expectedResp:       `{"error":"Failed to fetch status from db"}`,
+			expectedStatusCode: 500,
+		},
+	}
+
+	for _, test := range tests {
+		hHandler := HealthHandler{DB: test.mockDB, Version: "v1.0.0"}
+		router := mux.NewRouter()
+		hHandler.AddRoutes(router)
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest("GET", url, nil)
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPCache adds ETag, Last-Modified and Cache-Control headers to successful GET responses,
+// and answers conditional requests for unchanged data with 304 Not Modified
+type HTTPCache struct {
+	// LastSync returns when the data last changed, used for Last-Modified
+	LastSync func() time.Time
+	// MaxAge is how long clients may cache responses for
+	MaxAge time.Duration
+	// Private stops shared caches like CDNs from storing responses, set it when API keys are required
+	// so a response fetched with one client's key isn't served to another
+	Private bool
+}
+
+// bufferedResponseWriter holds onto a response so headers can be added after the body is known
+type bufferedResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(code int) {
+	b.code = code
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func makeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+}
+
+// etagMatches checks if the etag is in an If-None-Match header value, using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified checks the request's conditional headers. If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// Middleware adds caching headers to the responses of the next handler
+func (c *HTTPCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponseWriter{header: w.Header(), code: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		if buf.code != http.StatusOK {
+			w.WriteHeader(buf.code)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		etag := makeETag(buf.body.Bytes())
+		w.Header().Set("ETag", etag)
+		visibility := "public"
+		if c.Private {
+			visibility = "private"
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(c.MaxAge.Seconds())))
+
+		var lastModified time.Time
+		if c.LastSync != nil {
+			lastModified = c.LastSync()
+		}
+		if !lastModified.IsZero() {
+			w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+
+		if notModified(r, etag, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Write(buf.body.Bytes())
+	})
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_etagMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		etag        string
+		expected    bool
+	}{
+		{name: "Exact", ifNoneMatch: `"abc"`, etag: `"abc"`, expected: true},
+		{name: "Weak", ifNoneMatch: `W/"abc"`, etag: `"abc"`, expected: true},
+		{name: "List", ifNoneMatch: `"def", "abc"`, etag: `"abc"`, expected: true},
+		{name: "Star", ifNoneMatch: `*`, etag: `"abc"`, expected: true},
+		{name: "No match", ifNoneMatch: `"def"`, etag: `"abc"`, expected: false},
+	}
+
+	for _, test := range tests {
+		require.Equalf(t, test.expected, etagMatches(test.ifNoneMatch, test.etag), "test %q failed", test.name)
+	}
+}
+
+func Test_HTTPCache_Middleware(t *testing.T) {
+	lastSync := time.Date(2020, 9, 1, 12, 0, 0, 500, time.UTC)
+	body := `{"teams":[]}`
+	etag := makeETag([]byte(body))
+
+	tests := []struct {
+		name               string
+		method             string
+		headers            map[string]string
+		handlerStatusCode  int
+		expectedStatusCode int
+		expectedBody       string
+		expectCacheHeaders bool
+	}{
+		{
+			name:               "Plain request",
+			method:             "GET",
+			handlerStatusCode:  200,
+			expectedStatusCode: 200,
+			expectedBody:       body,
+			expectCacheHeaders: true,
+		},
+		{
+			name:               "Matching etag",
+			method:             "GET",
+			headers:            map[string]string{"If-None-Match": etag},
+			handlerStatusCode:  200,
+			expectedStatusCode: 304,
+			expectedBody:       "",
+			expectCacheHeaders: true,
+		},
+		{
+			name:               "Stale etag",
+			method:             "GET",
+			headers:            map[string]string{"If-None-Match": `"old"`, "If-Modified-Since": "Tue, 01 Sep 2020 12:00:00 GMT"},
+			handlerStatusCode:  200,
+			expectedStatusCode: 200,
+			expectedBody:       body,
+			expectCacheHeaders: true,
+		},
+		{
+			name:               "Not modified since",
+			method:             "GET",
+			headers:            map[string]string{"If-Modified-Since": "Tue, 01 Sep 2020 12:00:00 GMT"},
+			handlerStatusCode:  200,
+			expectedStatusCode: 304,
+			expectedBody:       "",
+			expectCacheHeaders: true,
+		},
+		{
+			name:               "Modified since",
+			method:             "GET",
+			headers:            map[string]string{"If-Modified-Since": "Tue, 01 Sep 2020 11:00:00 GMT"},
+			handlerStatusCode:  200,
+			expectedStatusCode: 200,
+			expectedBody:       body,
+			expectCacheHeaders: true,
+		},
+		{
+			name:               "Error responses aren't cached",
+			method:             "GET",
+			headers:            map[string]string{"If-None-Match": "*"},
+			handlerStatusCode:  500,
+			expectedStatusCode: 500,
+			expectedBody:       body,
+			expectCacheHeaders: false,
+		},
+		{
+			name:               "Non GET requests aren't cached",
+			method:             "POST",
+			handlerStatusCode:  200,
+			expectedStatusCode: 200,
+			expectedBody:       body,
+			expectCacheHeaders: false,
+		},
+	}
+
+	for _, test := range tests {
+		cache := HTTPCache{LastSync: func() time.Time { return lastSync }, MaxAge: time.Minute}
+		handler := cache.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.handlerStatusCode)
+			w.Write([]byte(body))
+		}))
+
+		req := httptest.NewRequest(test.method, "/team", nil)
+		for k, v := range test.headers {
+			req.Header.Set(k, v)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		require.Equalf(t, test.expectedStatusCode, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, test.expectedBody, recorder.Body.String(), "%q wrong body", test.name)
+		if test.expectCacheHeaders {
+			require.Equalf(t, etag, recorder.Header().Get("ETag"), "%q wrong etag", test.name)
+			require.Equalf(t, "Tue, 01 Sep 2020 12:00:00 GMT", recorder.Header().Get("Last-Modified"), "%q wrong last modified", test.name)
+			require.Equalf(t, "public, max-age=60", recorder.Header().Get("Cache-Control"), "%q wrong cache control", test.name)
+		} else {
+			require.Emptyf(t, recorder.Header().Get("ETag"), "%q should not have an etag", test.name)
+		}
+	}
+}
+
+func Test_HTTPCache_Middleware_v1Envelope(t *testing.T) {
+	lastSync := time.Now().Add(-90 * time.Second)
+	meta := ResponseMeta{LastSync: func() time.Time { return lastSync }, Season: "14"}
+	cache := HTTPCache{LastSync: meta.LastSync, MaxAge: time.Minute}
+	tHandler := TeamHandler{DB: getAllTeamsMockDB{t: t, resp: []models.Team{{TeamID: "1", Name: "A"}}}}
+
+	router := mux.NewRouter()
+	router.Use(meta.Middleware)
+	v1 := APIVersion{Name: "v1"}
+	subR := router.PathPrefix(v1.Prefix() + "/team").Subrouter()
+	subR.Use(v1.Middleware, cache.Middleware)
+	tHandler.AddRoutes(subR)
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest("GET", "/v1/team", nil))
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/v1/team", nil)
+	req.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, req)
+	require.Equal(t, http.StatusNotModified, second.Code)
+	require.Equal(t, etag, second.Header().Get("ETag"))
+}
+
+func Test_HTTPCache_Middleware_private(t *testing.T) {
+	cache := HTTPCache{MaxAge: 5 * time.Minute, Private: true}
+	handler := cache.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/team", nil))
+	require.Equal(t, "private, max-age=300", recorder.Header().Get("Cache-Control"))
+}
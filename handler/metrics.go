@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler serves prometheus metrics
+type MetricsHandler struct{}
+
+// AddRoutes adds all of it's routes to the router
+func (m *MetricsHandler) AddRoutes(router *mux.Router) {
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+}
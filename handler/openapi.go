@@ -0,0 +1,494 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/openapi"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate go run redoc_gen.go
+
+// redocVersion and redocBundle are the Redoc standalone script vendored into redoc_bundle.go by redoc_gen.go.
+// They're empty until it's generated, the docs are rendered by docsViewerScript until then.
+var redocVersion, redocBundle string
+
+// redocPage renders the spec with the Redoc bundle served at /docs/viewer.js
+const redocPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>RSC Spreadsheet API</title>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+  </head>
+  <body>
+    <redoc spec-url="/openapi.json"></redoc>
+    <script src="/docs/viewer.js"></script>
+  </body>
+</html>
+`
+
+// docsPage renders the spec with the fallback viewer served at /docs/viewer.js
+const docsPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>RSC Spreadsheet API</title>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <style>` + docsViewerStyle + `</style>
+  </head>
+  <body>
+    <div id="docs" data-spec-url="/openapi.json"></div>
+    <script src="/docs/viewer.js"></script>
+  </body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI spec and a page to browse it
+type DocsHandler struct {
+	Spec *openapi.Document
+}
+
+// AddRoutes adds all of it's routes to the router
+func (d *DocsHandler) AddRoutes(router *mux.Router) {
+	if d.Spec == nil {
+		log.Fatal("DocsHandler.Spec is nil!")
+	}
+
+	router.HandleFunc("/openapi.json", d.getSpec).Methods("GET")
+	router.HandleFunc("/docs", d.getDocs).Methods("GET")
+	router.HandleFunc("/docs/viewer.js", d.getViewer).Methods("GET")
+}
+
+func (d *DocsHandler) getSpec(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	if err := writeJSON(w, r, http.StatusOK, d.Spec); err != nil {
+		log.Errorf("Unable to marshal OpenAPI spec: %s", err)
+		writeError(w, "Error sending OpenAPI spec", http.StatusInternalServerError)
+	}
+}
+
+func (d *DocsHandler) getDocs(w http.ResponseWriter, r *http.Request) {
+	page := docsPage
+	if redocBundle != "" {
+		page = redocPage
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
+
+func (d *DocsHandler) getViewer(w http.ResponseWriter, r *http.Request) {
+	script := docsViewerScript
+	if redocBundle != "" {
+		script = redocBundle
+	}
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Write([]byte(script))
+}
+
+// specBuilder has shorthands for describing the routes of this API
+type specBuilder struct {
+	*openapi.Document
+}
+
+func (s specBuilder) jsonBody(v interface{}) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{jsonContentType: {Schema: s.SchemaFor(v)}}
+}
+
+func (s specBuilder) ok(description string, v interface{}) openapi.Response {
+	return openapi.Response{Description: description, Content: s.jsonBody(v)}
+}
+
+func (s specBuilder) err(description string) openapi.Response {
+	return openapi.Response{Description: description, Content: s.jsonBody(errorResp{})}
+}
+
+// envelope describes a v1 response wrapping data
+func (s specBuilder) envelope(data interface{}) *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data":  s.SchemaFor(data),
+			"meta":  s.SchemaFor(envelopeMeta{}),
+			"links": s.SchemaFor(envelopeLinks{}),
+		},
+		Required: []string{"data", "meta", "links"},
+	}
+}
+
+// versioned describes a JSON response that's the legacy body, or data in an envelope for versions that use them
+func (s specBuilder) versioned(v APIVersion, description string, legacy, data interface{}) openapi.Response {
+	if !usesEnvelope(v.Name) {
+		return s.ok(description, legacy)
+	}
+	return openapi.Response{Description: description, Content: map[string]openapi.MediaType{
+		jsonContentType: {Schema: s.envelope(data)},
+	}}
+}
+
+// pageParams are the params of lists paginated in versions that use envelopes
+func pageParams(v APIVersion) []openapi.Parameter {
+	if !usesEnvelope(v.Name) {
+		return nil
+	}
+	return []openapi.Parameter{
+		queryParam("offset", "How many items to skip", false),
+		queryParam("limit", "How many items to return, from 1 to 500, defaults to 100", false),
+	}
+}
+
+func queryParam(name, description string, multiple bool) openapi.Parameter {
+	schema := &openapi.Schema{Type: "string"}
+	if multiple {
+		schema = &openapi.Schema{Type: "array", Items: schema}
+	}
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func pathParam(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &openapi.Schema{Type: "string"}}
+}
+
+// APISpec describes every route served by the API. The team routes are documented under each of teamVersions
+// and the other versioned routes under each of apiVersions.
+func APISpec(version string, teamVersions, apiVersions []APIVersion) *openapi.Document {
+	s := specBuilder{openapi.NewDocument(openapi.Info{
+		Title:       "RSC Spreadsheet API",
+		Description: "Cleaned up data from the RSC spreadsheets. Add ?pretty=1 to any JSON request to indent the response.",
+		Version:     version,
+	})}
+
+	s.Components.SecuritySchemes["apiKeyHeader"] = openapi.SecurityScheme{Type: "apiKey", In: "header", Name: APIKeyHeader}
+	s.Components.SecuritySchemes["apiKeyQuery"] = openapi.SecurityScheme{Type: "apiKey", In: "query", Name: APIKeyQueryParam}
+	s.Components.SecuritySchemes["adminToken"] = openapi.SecurityScheme{Type: "http", Scheme: "bearer"}
+
+	s.Add("GET", "/healthz", openapi.Operation{
+		Summary:   "Check the API is running",
+		Tags:      []string{"health"},
+		Responses: map[string]openapi.Response{"200": s.ok("The API is running", healthResp{})},
+	})
+	s.Add("GET", "/readyz", openapi.Operation{
+		Summary: "Check the API can serve data",
+		Tags:    []string{"health"},
+		Responses: map[string]openapi.Response{
+			"200": s.ok("The db is reachable and has been synced", healthResp{}),
+			"503": s.err("The db is unreachable or hasn't been synced"),
+		},
+	})
+	s.Add("GET", "/status", openapi.Operation{
+		Summary: "Get sync and db status",
+		Tags:    []string{"health"},
+		Responses: map[string]openapi.Response{
+			"200": s.ok("Status of the API", statusResp{}),
+			"500": s.err("Failed to fetch status"),
+		},
+	})
+	s.Add("GET", "/metrics", openapi.Operation{
+		Summary:   "Get Prometheus metrics",
+		Tags:      []string{"health"},
+		Responses: map[string]openapi.Response{"200": {Description: "Metrics in the Prometheus text format"}},
+	})
+	s.Add("GET", "/openapi.json", openapi.Operation{
+		Summary:   "Get this OpenAPI document",
+		Tags:      []string{"docs"},
+		Responses: map[string]openapi.Response{"200": {Description: "The OpenAPI document"}},
+	})
+	s.Add("GET", "/docs", openapi.Operation{
+		Summary:   "Browse the API docs",
+		Tags:      []string{"docs"},
+		Responses: map[string]openapi.Response{"200": {Description: "An HTML page rendering the OpenAPI document"}},
+	})
+	s.Add("GET", "/docs/viewer.js", openapi.Operation{
+		Summary:   "Get the script that renders the API docs",
+		Tags:      []string{"docs"},
+		Responses: map[string]openapi.Response{"200": {Description: "The vendored Redoc bundle, served with the docs page so it works without a CDN"}},
+	})
+
+	for _, v := range teamVersions {
+		s.addTeamRoutes(v)
+	}
+	for _, v := range apiVersions {
+		s.addVersionedRoutes(v)
+	}
+
+	return s.Document
+}
+
+// spreadsheetMediaTypes are the formats lists can be exported as
+var spreadsheetMediaTypes = map[string]openapi.MediaType{
+	csvContentType:  {Schema: &openapi.Schema{Type: "string"}},
+	xlsxContentType: {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+}
+
+// teamFilterParams are the filters of lists of teams and their standings
+func teamFilterParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		queryParam("id", "Team IDs, must be integers", true),
+		queryParam("name", "Team names", true),
+		queryParam("franchise", "Franchise names", true),
+		queryParam("conference", "Conference names", true),
+		queryParam("tier", "Tier names", true),
+		queryParam("division", "Division names", true),
+		{Name: formatQueryParam, In: "query", Description: "Response format, overrides the Accept header", Schema: &openapi.Schema{Type: "string", Enum: []string{formatJSON, formatCSV, formatXLSX}}},
+	}
+}
+
+// add documents an operation of an API version
+func (s specBuilder) add(v APIVersion, method, path string, op openapi.Operation) {
+	op.Deprecated = v.Deprecated()
+	s.Add(method, v.Prefix()+path, op)
+}
+
+// addTeamRoutes documents the team routes mounted under an API version, the only ones also served unversioned
+func (s specBuilder) addTeamRoutes(v APIVersion) {
+	apiKey := []map[string][]string{{"apiKeyHeader": {}}, {"apiKeyQuery": {}}, {}}
+	rateLimited := s.err("Rate limit exceeded")
+	unauthorized := s.err("Missing or invalid credentials")
+	// the serializers' outputs are reflected so the schemas match what they write
+	teamSerializer := teamSerializerForVersion(v.Name)
+
+	s.add(v, "GET", "/team", openapi.Operation{
+		Summary:     "List teams",
+		Description: "Every filter can be repeated to match any of the values. Different filters must all match.",
+		Tags:        []string{"teams"},
+		Parameters:  append(teamFilterParams(), pageParams(v)...),
+		Responses: map[string]openapi.Response{
+			"200": {Description: "Teams matching the filters", Content: map[string]openapi.MediaType{
+				jsonContentType: s.versioned(v, "", teamSerializer.list(nil, nil), teamSerializer.list(nil, nil)).Content[jsonContentType],
+				csvContentType:  spreadsheetMediaTypes[csvContentType],
+				xlsxContentType: spreadsheetMediaTypes[xlsxContentType],
+			}},
+			"304": {Description: "The teams haven't changed since the ETag or date in the conditional headers"},
+			"400": s.err("Invalid filters or format"),
+			"401": unauthorized,
+			"429": rateLimited,
+			"500": s.err("Failed to fetch teams"),
+		},
+		Security: apiKey,
+	})
+	s.add(v, "GET", "/team/{id}", openapi.Operation{
+		Summary:    "Get a team",
+		Tags:       []string{"teams"},
+		Parameters: []openapi.Parameter{pathParam("id", "Team ID, must be an integer")},
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "The team", teamSerializer.single(models.Team{}, nil), teamSerializer.single(models.Team{}, nil)),
+			"304": {Description: "The team hasn't changed since the ETag or date in the conditional headers"},
+			"400": s.err("Team ID isn't an integer"),
+			"401": unauthorized,
+			"404": s.err("Team not found"),
+			"429": rateLimited,
+			"500": s.err("Failed to fetch the team"),
+		},
+		Security: apiKey,
+	})
+}
+
+// addVersionedRoutes documents the other routes mounted under an API version
+func (s specBuilder) addVersionedRoutes(v APIVersion) {
+	apiKey := []map[string][]string{{"apiKeyHeader": {}}, {"apiKeyQuery": {}}, {}}
+	admin := []map[string][]string{{"adminToken": {}}}
+	rateLimited := s.err("Rate limit exceeded")
+	unauthorized := s.err("Missing or invalid credentials")
+
+	s.add(v, "GET", "/standings", openapi.Operation{
+		Summary:     "List standings",
+		Description: "Filters the standings by their teams, every filter can be repeated to match any of the values. Different filters must all match.",
+		Tags:        []string{"standings"},
+		Parameters:  append(teamFilterParams(), pageParams(v)...),
+		Responses: map[string]openapi.Response{
+			"200": {Description: "Standings of the teams matching the filters", Content: map[string]openapi.MediaType{
+				jsonContentType: s.versioned(v, "", standingsListResp{}, []models.Standing{}).Content[jsonContentType],
+				csvContentType:  spreadsheetMediaTypes[csvContentType],
+				xlsxContentType: spreadsheetMediaTypes[xlsxContentType],
+			}},
+			"304": {Description: "The standings haven't changed since the ETag or date in the conditional headers"},
+			"400": s.err("Invalid filters or format"),
+			"401": unauthorized,
+			"429": rateLimited,
+			"500": s.err("Failed to fetch standings"),
+		},
+		Security: apiKey,
+	})
+	s.add(v, "GET", "/standings/{id}", openapi.Operation{
+		Summary:    "Get a team's standing",
+		Tags:       []string{"standings"},
+		Parameters: []openapi.Parameter{pathParam("id", "Team ID, must be an integer")},
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "The team's standing", models.Standing{}, models.Standing{}),
+			"304": {Description: "The standing hasn't changed since the ETag or date in the conditional headers"},
+			"400": s.err("Team ID isn't an integer"),
+			"401": unauthorized,
+			"404": s.err("The team doesn't have a standing"),
+			"429": rateLimited,
+			"500": s.err("Failed to fetch the standing"),
+		},
+		Security: apiKey,
+	})
+
+	s.add(v, "GET", "/events", openapi.Operation{
+		Summary:     "Stream changes found by syncs",
+		Description: "Server-Sent Events. Reconnecting with Last-Event-ID replays missed events.",
+		Tags:        []string{"events"},
+		Parameters: []openapi.Parameter{
+			queryParam("tier", "Only events for teams in these tiers", true),
+			queryParam("franchise", "Only events for teams in these franchises", true),
+			queryParam("team", "Only events for these team IDs", true),
+			queryParam("last_event_id", "Resume after this event ID, for clients that can't set Last-Event-ID", false),
+			{Name: "Last-Event-ID", In: "header", Description: "Resume after this event ID", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "A stream of events", Content: map[string]openapi.MediaType{
+				"text/event-stream": {Schema: s.SchemaFor(models.Event{})},
+			}},
+			"400": s.err("Invalid last event ID"),
+			"401": unauthorized,
+			"429": rateLimited,
+		},
+		Security: apiKey,
+	})
+
+	graphQLResponses := map[string]openapi.Response{
+		"200": {Description: "Query result, errors are listed in the body", Content: map[string]openapi.MediaType{
+			jsonContentType: {Schema: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+				"data":   {Type: "object"},
+				"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+			}}},
+		}},
+		"400": s.err("Missing or malformed query"),
+		"401": unauthorized,
+		"429": rateLimited,
+	}
+	s.add(v, "GET", "/graphql", openapi.Operation{
+		Summary: "Run a GraphQL query",
+		Tags:    []string{"graphql"},
+		Parameters: []openapi.Parameter{
+			queryParam("query", "The GraphQL query", false),
+			queryParam("operationName", "Which operation in the query to run", false),
+			queryParam("variables", "JSON object of variables", false),
+		},
+		Responses: graphQLResponses,
+		Security:  apiKey,
+	})
+	s.add(v, "POST", "/graphql", openapi.Operation{
+		Summary:     "Run a GraphQL query",
+		Tags:        []string{"graphql"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: s.jsonBody(graphQLRequest{})},
+		Responses:   graphQLResponses,
+		Security:    apiKey,
+	})
+
+	s.add(v, "POST", "/admin/sync", openapi.Operation{
+		Summary:    "Sync sources now",
+		Tags:       []string{"admin"},
+		Parameters: []openapi.Parameter{queryParam("source", "Only sync this source", false)},
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "Every source synced", syncRunsResp{}, []models.SyncRun{}),
+			"401": unauthorized,
+			"404": s.err("Unknown source"),
+			"502": s.versioned(v, "A source failed to sync", syncRunsResp{}, []models.SyncRun{}),
+		},
+		Security: admin,
+	})
+	s.add(v, "GET", "/admin/sync/runs", openapi.Operation{
+		Summary:    "List recent sync runs",
+		Tags:       []string{"admin"},
+		Parameters: []openapi.Parameter{queryParam("limit", "How many runs to return, from 1 to 100", false)},
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "Sync runs, newest first", syncRunsResp{}, []models.SyncRun{}),
+			"400": s.err("Invalid limit"),
+			"401": unauthorized,
+			"500": s.err("Failed to fetch sync runs"),
+		},
+		Security: admin,
+	})
+	s.add(v, "DELETE", "/admin/cache", openapi.Operation{
+		Summary: "Clear the response caches",
+		Tags:    []string{"admin"},
+		Responses: map[string]openapi.Response{
+			"204": {Description: "Caches cleared"},
+			"401": unauthorized,
+		},
+		Security: admin,
+	})
+
+	s.add(v, "POST", "/admin/keys", openapi.Operation{
+		Summary:     "Issue an API key",
+		Description: "The raw key is only returned here, only its hash is stored.",
+		Tags:        []string{"keys"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: s.jsonBody(createAPIKeyReq{})},
+		Responses: map[string]openapi.Response{
+			"201": s.versioned(v, "The key, including the raw key to send in the API key header", createdAPIKey{}, createdAPIKey{}),
+			"400": s.err("Missing name or owner, or an invalid rate limit"),
+			"401": unauthorized,
+			"500": s.err("Failed to create the key"),
+		},
+		Security: admin,
+	})
+	s.add(v, "DELETE", "/admin/keys/{id}", openapi.Operation{
+		Summary:    "Revoke an API key",
+		Tags:       []string{"keys"},
+		Parameters: []openapi.Parameter{pathParam("id", "API key ID")},
+		Responses: map[string]openapi.Response{
+			"204": {Description: "Key revoked"},
+			"400": s.err("API key ID isn't an integer"),
+			"401": unauthorized,
+			"404": s.err("Key not found or already revoked"),
+			"500": s.err("Failed to revoke the key"),
+		},
+		Security: admin,
+	})
+
+	s.add(v, "GET", "/admin/webhooks", openapi.Operation{
+		Summary:    "List webhook subscriptions",
+		Tags:       []string{"webhooks"},
+		Parameters: pageParams(v),
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "Subscriptions, without their secrets", webhookSubscriptionsResp{}, []models.WebhookSubscription{}),
+			"401": unauthorized,
+			"500": s.err("Failed to fetch subscriptions"),
+		},
+		Security: admin,
+	})
+	s.add(v, "POST", "/admin/webhooks", openapi.Operation{
+		Summary:     "Subscribe a URL to events",
+		Description: "An empty event_types list subscribes to every event type.",
+		Tags:        []string{"webhooks"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: s.jsonBody(createWebhookReq{})},
+		Responses: map[string]openapi.Response{
+			"201": s.versioned(v, "The subscription, including the secret used to sign deliveries", models.WebhookSubscription{}, models.WebhookSubscription{}),
+			"400": s.err("Invalid URL or event type"),
+			"401": unauthorized,
+			"500": s.err("Failed to create the subscription"),
+		},
+		Security: admin,
+	})
+	s.add(v, "DELETE", "/admin/webhooks/{id}", openapi.Operation{
+		Summary:    "Delete a webhook subscription",
+		Tags:       []string{"webhooks"},
+		Parameters: []openapi.Parameter{pathParam("id", "Subscription ID")},
+		Responses: map[string]openapi.Response{
+			"204": {Description: "Subscription deleted"},
+			"400": s.err("Subscription ID isn't an integer"),
+			"401": unauthorized,
+			"404": s.err("Subscription not found"),
+			"500": s.err("Failed to delete the subscription"),
+		},
+		Security: admin,
+	})
+	s.add(v, "GET", "/admin/webhooks/{id}/deliveries", openapi.Operation{
+		Summary:    "List recent deliveries of a webhook subscription",
+		Tags:       []string{"webhooks"},
+		Parameters: []openapi.Parameter{pathParam("id", "Subscription ID")},
+		Responses: map[string]openapi.Response{
+			"200": s.versioned(v, "Delivery attempts, newest first", webhookDeliveriesResp{}, []models.WebhookDelivery{}),
+			"400": s.err("Subscription ID isn't an integer"),
+			"401": unauthorized,
+			"500": s.err("Failed to fetch deliveries"),
+		},
+		Security: admin,
+	})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/openapi"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DocsHandler_AddRoutes_NilSpec(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	dHandler := DocsHandler{}
+	dHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_DocsHandler(t *testing.T) {
+	origBundle := redocBundle
+	defer func() { redocBundle = origBundle }()
+	redocBundle = ""
+
+	dHandler := DocsHandler{Spec: APISpec("1.2.3", []APIVersion{{Name: "v1"}}, []APIVersion{{Name: "v1"}})}
+	router := mux.NewRouter()
+	dHandler.AddRoutes(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	spec := openapi.Document{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	require.Equal(t, openapi.Version, spec.OpenAPI)
+	require.Equal(t, "1.2.3", spec.Info.Version)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/docs", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `data-spec-url="/openapi.json"`)
+	require.Contains(t, recorder.Body.String(), `<script src="/docs/viewer.js">`)
+	require.NotContains(t, recorder.Body.String(), "https://")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/docs/viewer.js", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/javascript; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Equal(t, docsViewerScript, recorder.Body.String())
+}
+
+func Test_APISpec_refsResolve(t *testing.T) {
+	spec := APISpec("dev", []APIVersion{{Name: "v1"}, {Name: LegacyVersion, Deprecation: time.Now(), Successor: "v1"}}, []APIVersion{{Name: "v1"}})
+	raw, err := json.Marshal(spec)
+	require.NoError(t, err)
+
+	refs := regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(raw), -1)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		require.Containsf(t, spec.Components.Schemas, ref[1], "schema %s is referenced but not defined", ref[1])
+	}
+
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			require.NotEmptyf(t, op.Summary, "%s %s has no summary", method, path)
+			require.NotEmptyf(t, op.Responses, "%s %s has no responses", method, path)
+		}
+	}
+}
+
+func Test_APISpec_teamSchema(t *testing.T) {
+	spec := APISpec("dev", []APIVersion{{Name: "v1"}, {Name: LegacyVersion, Deprecation: time.Now(), Successor: "v1"}}, []APIVersion{{Name: "v1"}})
+
+	team := spec.Components.Schemas["Team"]
+	require.NotNil(t, team)
+	require.ElementsMatch(t, []string{"id", "name", "franchise", "tier", "conference", "division"}, keys(team.Properties))
+	require.True(t, team.Properties["division"].Nullable)
+	require.NotContains(t, team.Required, "division")
+}
+
+func keys(m map[string]*openapi.Schema) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func Test_APISpec_versions(t *testing.T) {
+	spec := APISpec("dev", []APIVersion{{Name: "v1"}, {Name: LegacyVersion, Deprecation: time.Now(), Successor: "v1"}}, []APIVersion{{Name: "v1"}})
+
+	require.False(t, spec.Paths["/v1/team"]["get"].Deprecated)
+	require.True(t, spec.Paths["/team"]["get"].Deprecated)
+	require.NotContains(t, spec.Paths, "/v1/healthz")
+	require.Contains(t, spec.Paths, "/v1/standings")
+	require.NotContains(t, spec.Paths, "/standings")
+	require.NotContains(t, spec.Paths, "/admin/keys")
+
+	list := spec.Paths["/v1/team"]["get"].Responses["200"].Content["application/json"].Schema
+	require.Equal(t, "#/components/schemas/teamV1", list.Properties["data"].Items.Ref)
+	require.Equal(t, "#/components/schemas/envelopeMeta", list.Properties["meta"].Ref)
+	legacyList := spec.Paths["/team"]["get"].Responses["200"].Content["application/json"].Schema
+	require.Equal(t, "#/components/schemas/teamsListResp", legacyList.Ref)
+	require.Contains(t, spec.Components.Schemas["teamV1"].Properties, "standing")
+	require.Contains(t, spec.Components.Schemas["teamV1"].Properties, "franchise")
+}
+
+func Test_DocsHandler_redoc(t *testing.T) {
+	origVersion, origBundle := redocVersion, redocBundle
+	defer func() { redocVersion, redocBundle = origVersion, origBundle }()
+	redocVersion, redocBundle = "2.1.5", "/* redoc */"
+
+	dHandler := DocsHandler{Spec: APISpec("1.2.3", []APIVersion{{Name: "v1"}}, []APIVersion{{Name: "v1"}})}
+	router := mux.NewRouter()
+	dHandler.AddRoutes(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/docs", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `<redoc spec-url="/openapi.json"></redoc>`)
+	require.Contains(t, recorder.Body.String(), `<script src="/docs/viewer.js">`)
+	require.NotContains(t, recorder.Body.String(), "https://")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/docs/viewer.js", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "/* redoc */", recorder.Body.String())
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// maxBuckets is how many buckets are kept before full ones start getting cleaned up
+const maxBuckets = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket rate limiter with a bucket per client.
+// Each bucket holds up to a minute's worth of requests and refills continuously.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// rateLimitResult is the state of a bucket after trying to take a token
+type rateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// allow tries to take a token from the bucket for id, which allows perMinute requests a minute.
+// A limit of 0 or less blocks every request, there's no time after which a retry would be allowed.
+func (l *rateLimiter) allow(id string, perMinute int) rateLimitResult {
+	return l.take(id, perMinute, true)
+}
+
+// check is allow without taking a token, for seeing if a request would be allowed before doing any work for it
+func (l *rateLimiter) check(id string, perMinute int) rateLimitResult {
+	return l.take(id, perMinute, false)
+}
+
+func (l *rateLimiter) take(id string, perMinute int, consume bool) rateLimitResult {
+	if perMinute <= 0 {
+		return rateLimitResult{Limit: 0}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(perMinute)
+	perSecond := capacity / 60
+
+	b, ok := l.buckets[id]
+	if !ok && !consume {
+		// a missing bucket is full
+		return rateLimitResult{Allowed: true, Limit: perMinute, Remaining: perMinute}
+	}
+	if !ok {
+		l.cleanup(now, perSecond, capacity)
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[id] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	result := rateLimitResult{Limit: perMinute}
+	if b.tokens >= 1 {
+		if consume {
+			b.tokens--
+		}
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / perSecond)
+
+	return result
+}
+
+// cleanup drops buckets that have refilled, once there are too many of them
+func (l *rateLimiter) cleanup(now time.Time, perSecond, capacity float64) {
+	if len(l.buckets) < maxBuckets {
+		return
+	}
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*perSecond >= capacity {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
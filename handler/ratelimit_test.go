@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rateLimiter_allow(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		result := limiter.allow("a", 2)
+		require.Equal(t, true, result.Allowed)
+		require.Equal(t, 1-i, result.Remaining)
+	}
+
+	result := limiter.allow("a", 2)
+	require.Equal(t, rateLimitResult{
+		Allowed:    false,
+		Limit:      2,
+		Remaining:  0,
+		Reset:      60 * time.Second,
+		RetryAfter: 30 * time.Second,
+	}, result)
+
+	// other clients get their own bucket
+	require.Equal(t, true, limiter.allow("b", 2).Allowed)
+
+	now = now.Add(30 * time.Second)
+	result = limiter.allow("a", 2)
+	require.Equal(t, true, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+}
+
+func Test_rateLimiter_check(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// checking doesn't make a bucket or take a token
+	require.Equal(t, true, limiter.check("a", 1).Allowed)
+	require.Empty(t, limiter.buckets)
+
+	require.Equal(t, true, limiter.allow("a", 1).Allowed)
+	result := limiter.check("a", 1)
+	require.Equal(t, false, result.Allowed)
+	require.Equal(t, 60*time.Second, result.RetryAfter)
+
+	now = now.Add(time.Minute)
+	require.Equal(t, true, limiter.check("a", 1).Allowed)
+	require.Equal(t, true, limiter.check("a", 1).Allowed)
+}
+
+func Test_rateLimiter_allow_zeroLimit(t *testing.T) {
+	limiter := newRateLimiter()
+	for _, limit := range []int{0, -1} {
+		require.Equal(t, rateLimitResult{Allowed: false}, limiter.allow("a", limit))
+	}
+	require.Empty(t, limiter.buckets)
+}
+
+func Test_rateLimiter_cleanup(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < maxBuckets; i++ {
+		limiter.buckets[string(rune(i))] = &bucket{tokens: 10, last: now}
+	}
+	limiter.buckets["empty"] = &bucket{tokens: 0, last: now}
+
+	limiter.allow("new", 10)
+
+	require.Len(t, limiter.buckets, 2)
+}
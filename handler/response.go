@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const jsonContentType = "application/json"
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 500
+)
+
+// ResponseMeta is the metadata added to every envelope
+type ResponseMeta struct {
+	// LastSync returns when the data last changed. Syncs that found nothing new
+	// shouldn't move it, or every sync would change the body and its ETag.
+	LastSync func() time.Time
+	// Season is the RSC season the data is from, left out if empty
+	Season string
+}
+
+type responseMetaCtxKey struct{}
+
+// Middleware makes the metadata available to the handlers writing envelopes
+func (m ResponseMeta) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), responseMetaCtxKey{}, m)))
+	})
+}
+
+func responseMetaFromContext(ctx context.Context) ResponseMeta {
+	m, _ := ctx.Value(responseMetaCtxKey{}).(ResponseMeta)
+	return m
+}
+
+// usesEnvelope checks if an API version wraps its responses in an envelope.
+// The legacy version keeps its original bare responses so old clients don't break.
+func usesEnvelope(version string) bool {
+	return version != LegacyVersion
+}
+
+// envelope wraps the data of a response with metadata and links
+type envelope struct {
+	Data  interface{}   `json:"data"`
+	Meta  envelopeMeta  `json:"meta"`
+	Links envelopeLinks `json:"links"`
+}
+
+type envelopeMeta struct {
+	// Total is how many items there are across every page, left out if it isn't known
+	Total *int      `json:"total,omitempty"`
+	Page  *pageMeta `json:"page,omitempty"`
+	// LastSync is when a sync last changed the data. Clients work out the data's age from it, nothing in the body
+	// depends on the current time so the ETag stays the same until the data changes.
+	LastSync *time.Time `json:"last_sync,omitempty"`
+	Season   string     `json:"season,omitempty"`
+}
+
+type pageMeta struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Count  int `json:"count"`
+}
+
+type envelopeLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// page is the part of a list a request asked for
+type page struct {
+	Offset int
+	Limit  int
+}
+
+// parsePage reads ?offset and ?limit, limit defaults to defaultPageLimit and can't be over maxPageLimit
+func parsePage(r *http.Request) (page, bool) {
+	p := page{Offset: 0, Limit: defaultPageLimit}
+	query := r.URL.Query()
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return p, false
+		}
+		p.Offset = offset
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return p, false
+		}
+		p.Limit = limit
+	}
+	return p, true
+}
+
+// bounds gets the slice indexes of the page in a list of total items
+func (p page) bounds(total int) (int, int) {
+	start, end := p.Offset, p.Offset+p.Limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageURL is the request's URL pointing at another page
+func pageURL(r *http.Request, offset, limit int) string {
+	u := *r.URL
+	query := u.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+func newEnvelopeMeta(r *http.Request) envelopeMeta {
+	m := responseMetaFromContext(r.Context())
+	meta := envelopeMeta{Season: m.Season}
+	if m.LastSync != nil {
+		if lastSync := m.LastSync(); !lastSync.IsZero() {
+			lastSync = lastSync.UTC()
+			meta.LastSync = &lastSync
+		}
+	}
+	return meta
+}
+
+// newItemEnvelope wraps a single item
+func newItemEnvelope(r *http.Request, data interface{}) *envelope {
+	return &envelope{
+		Data:  data,
+		Meta:  newEnvelopeMeta(r),
+		Links: envelopeLinks{Self: r.URL.RequestURI()},
+	}
+}
+
+// newPageEnvelope wraps one page of a list of total items, with links to the first, previous and next pages
+func newPageEnvelope(r *http.Request, data interface{}, p page, count, total int) *envelope {
+	e := newItemEnvelope(r, data)
+	e.Meta.Total = &total
+	e.Meta.Page = &pageMeta{Offset: p.Offset, Limit: p.Limit, Count: count}
+
+	e.Links.First = pageURL(r, 0, p.Limit)
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		e.Links.Prev = pageURL(r, prev, p.Limit)
+	}
+	if p.Offset+count < total {
+		e.Links.Next = pageURL(r, p.Offset+count, p.Limit)
+	}
+	return e
+}
+
+// newListEnvelope wraps a list that was cut off at a limit, so the total isn't known
+func newListEnvelope(r *http.Request, data interface{}, limit, count int) *envelope {
+	e := newItemEnvelope(r, data)
+	e.Meta.Page = &pageMeta{Offset: 0, Limit: limit, Count: count}
+	return e
+}
+
+// wantsPretty checks for ?pretty=1, or any other true value
+func wantsPretty(r *http.Request) bool {
+	pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+	return pretty
+}
+
+// writeJSON marshals v as the response body with the JSON content type, indented if the request asked for ?pretty=1
+func writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, v interface{}) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// links hold query strings, keep their &s readable
+	enc.SetEscapeHTML(false)
+	if wantsPretty(r) {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(statusCode)
+	w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePage(t *testing.T) {
+	tests := []struct {
+		requestPath string
+		expected    page
+		expectedOK  bool
+	}{
+		{requestPath: "/v1/team", expected: page{Offset: 0, Limit: 100}, expectedOK: true},
+		{requestPath: "/v1/team?offset=20&limit=10", expected: page{Offset: 20, Limit: 10}, expectedOK: true},
+		{requestPath: "/v1/team?limit=500", expected: page{Offset: 0, Limit: 500}, expectedOK: true},
+		{requestPath: "/v1/team?limit=501", expectedOK: false},
+		{requestPath: "/v1/team?limit=abc", expectedOK: false},
+		{requestPath: "/v1/team?offset=-1", expectedOK: false},
+	}
+
+	for _, test := range tests {
+		actual, ok := parsePage(httptest.NewRequest("GET", test.requestPath, nil))
+		require.Equalf(t, test.expectedOK, ok, "%q wrong ok", test.requestPath)
+		if ok {
+			require.Equalf(t, test.expected, actual, "%q wrong page", test.requestPath)
+		}
+	}
+}
+
+func Test_page_bounds(t *testing.T) {
+	start, end := page{Offset: 10, Limit: 5}.bounds(12)
+	require.Equal(t, 10, start)
+	require.Equal(t, 12, end)
+
+	start, end = page{Offset: 20, Limit: 5}.bounds(12)
+	require.Equal(t, 12, start)
+	require.Equal(t, 12, end)
+}
+
+func Test_newPageEnvelope(t *testing.T) {
+	lastSync := time.Now().Add(-90 * time.Second)
+	meta := ResponseMeta{LastSync: func() time.Time { return lastSync }, Season: "14"}
+
+	var actual *envelope
+	handler := meta.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actual = newPageEnvelope(r, []string{"b", "c"}, page{Offset: 1, Limit: 2}, 2, 5)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/team?tier=Master&offset=1&limit=2", nil))
+
+	require.Equal(t, []string{"b", "c"}, actual.Data)
+	require.Equal(t, 5, *actual.Meta.Total)
+	require.Equal(t, &pageMeta{Offset: 1, Limit: 2, Count: 2}, actual.Meta.Page)
+	require.Equal(t, "14", actual.Meta.Season)
+	require.Equal(t, lastSync.UTC(), *actual.Meta.LastSync)
+	require.Equal(t, envelopeLinks{
+		Self:  "/v1/team?tier=Master&offset=1&limit=2",
+		First: "/v1/team?limit=2&offset=0&tier=Master",
+		Prev:  "/v1/team?limit=2&offset=0&tier=Master",
+		Next:  "/v1/team?limit=2&offset=3&tier=Master",
+	}, actual.Links)
+}
+
+func Test_newItemEnvelope_notSynced(t *testing.T) {
+	meta := ResponseMeta{LastSync: func() time.Time { return time.Time{} }}
+
+	var actual *envelope
+	handler := meta.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actual = newItemEnvelope(r, "a")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/team/1", nil))
+
+	require.Equal(t, envelopeMeta{}, actual.Meta)
+	require.Equal(t, envelopeLinks{Self: "/v1/team/1"}, actual.Links)
+}
+
+func Test_writeJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestPath  string
+		expectedResp string
+	}{
+		{
+			name:         "Compact",
+			requestPath:  "/team",
+			expectedResp: `{"status":"a&b"}`,
+		},
+		{
+			name:         "Pretty",
+			requestPath:  "/team?pretty=1",
+			expectedResp: "{\n  \"status\": \"a&b\"\n}",
+		},
+		{
+			name:         "Pretty false",
+			requestPath:  "/team?pretty=false",
+			expectedResp: `{"status":"a&b"}`,
+		},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		err := writeJSON(recorder, httptest.NewRequest("GET", test.requestPath, nil), http.StatusAccepted, &healthResp{Status: "a&b"})
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, http.StatusAccepted, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, "application/json", recorder.Header().Get("Content-Type"), "%q wrong content type", test.name)
+		require.Equalf(t, test.expectedResp, recorder.Body.String(), "%q wrong resp", test.name)
+	}
+}
+
+func Test_writeError_contentType(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeError(recorder, "Team not found", http.StatusNotFound)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.Equal(t, `{"error":"Team not found"}`, recorder.Body.String())
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/tabular"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// StandingsHandler has all routes for standings related queries
+type StandingsHandler struct {
+	DB db.StandingsStore
+}
+
+// AddRoutes adds all of it's routes to the router
+func (s *StandingsHandler) AddRoutes(router *mux.Router) {
+	if s.DB == nil {
+		log.Fatal("StandingsHandler.DB is nil!")
+	}
+
+	router.HandleFunc("", s.getAllStandings).Methods("GET")
+	router.HandleFunc("/", s.getAllStandings).Methods("GET")
+	router.HandleFunc("/{id}", s.getStanding).Methods("GET")
+}
+
+type standingsListResp struct {
+	Standings []models.Standing `json:"standings"`
+}
+
+func (s *StandingsHandler) getAllStandings(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		log.Errorf("Invalid URL query string: %s", err)
+		writeError(w, "Invalid query", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeError(w, "format must be one of json, csv or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	version := VersionFromContext(r.Context())
+	p, ok := parsePage(r)
+	if !ok && usesEnvelope(version) {
+		writeError(w, "offset must be a positive integer and limit an integer from 1 to 500", http.StatusBadRequest)
+		return
+	}
+
+	standings, err := s.DB.GetAllStandings(r.Context(), teamsQueryFromForm(r))
+	if err == db.ErrInvalidTypeForQuery {
+		log.Warn("Invalid query param for standings")
+		writeError(w, "Team IDs must be integers", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Errorf("Unable to fetch standings from db: %s", err)
+		writeError(w, "Failed to fetch standings from db", http.StatusInternalServerError)
+		return
+	}
+
+	if format != formatJSON {
+		if err := writeTable(w, format, tabular.Standings(standings)); err != nil {
+			log.Errorf("Unable to write standings as %s: %s", format, err)
+		}
+		return
+	}
+
+	var body interface{} = &standingsListResp{Standings: standings}
+	if usesEnvelope(version) {
+		total := len(standings)
+		start, end := p.bounds(total)
+		body = newPageEnvelope(r, standings[start:end], p, end-start, total)
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
+		log.Errorf("Unable to marshal standings: %s", err)
+		writeError(w, "Error sending standings", http.StatusInternalServerError)
+	}
+}
+
+func (s *StandingsHandler) getStanding(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	teamID := mux.Vars(r)["id"]
+	standings, err := s.DB.GetStandingsByTeamIDs(r.Context(), []string{teamID})
+	if err == db.ErrInvalidTypeForQuery {
+		log.Warnf("Invalid team id: %s", teamID)
+		writeError(w, "Team ID must be an integer", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Errorf("Unable to fetch standing from db: %s", err)
+		writeError(w, "Failed to fetch standing from db", http.StatusInternalServerError)
+		return
+	}
+
+	if len(standings) == 0 {
+		writeError(w, "Standing not found", http.StatusNotFound)
+		return
+	}
+
+	var body interface{} = &standings[0]
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newItemEnvelope(r, body)
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
+		log.Errorf("Unable to marshal standing: %s", err)
+		writeError(w, "Error sending standing", http.StatusInternalServerError)
+	}
+}
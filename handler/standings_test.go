@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type getAllStandingsMockDB struct {
+	t                *testing.T
+	expectedQueryVal db.GetAllTeamsQuery
+	expectedTeamIDs  []string
+	resp             []models.Standing
+	err              error
+}
+
+func (m getAllStandingsMockDB) GetAllStandings(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Standing, error) {
+	require.Equal(m.t, m.expectedQueryVal, query)
+	return m.resp, m.err
+}
+
+func (m getAllStandingsMockDB) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	require.Equal(m.t, m.expectedTeamIDs, teamIDs)
+	return m.resp, m.err
+}
+
+func Test_StandingsHandler_AddRoutes_NilDB(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	sHandler := StandingsHandler{}
+	sHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_StandingsHandler(t *testing.T) {
+	standings := []models.Standing{
+		{
+			Team:             models.Team{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D"},
+			OverallRecord:    models.Record{Wins: 5, Losses: 1},
+			ConferenceRecord: models.Record{Wins: 3, Losses: 1},
+			SeriesRecord:     models.Record{Wins: 2, Losses: 0},
+			WinPercentage:    0.833,
+			GameDifferential: 4,
+			Streak:           "W3",
+			Seed:             intPointer(1),
+		},
+		{
+			Team:             models.Team{TeamID: "2", Name: "F", Franchise: "B", Conference: "C", Tier: "D"},
+			OverallRecord:    models.Record{Wins: 1, Losses: 5},
+			ConferenceRecord: models.Record{Wins: 1, Losses: 3},
+			SeriesRecord:     models.Record{Wins: 0, Losses: 2},
+			WinPercentage:    0.167,
+			GameDifferential: -4,
+			GamesBehind:      4,
+			Streak:           "L2",
+		},
+	}
+	standingOne := `{"team":{"id":"1","name":"A","franchise":"B","tier":"D","conference":"C"},"overall_record":{"wins":5,"losses":1},"conference_record":{"wins":3,"losses":1},` +
+		`"series_record":{"wins":2,"losses":0},"win_percentage":0.833,"game_differential":4,"games_behind":0,"streak":"W3","seed":1}`
+	standingTwo := `{"team":{"id":"2","name":"F","franchise":"B","tier":"D","conference":"C"},"overall_record":{"wins":1,"losses":5},"conference_record":{"wins":1,"losses":3},` +
+		`"series_record":{"wins":0,"losses":2},"win_percentage":0.167,"game_differential":-4,"games_behind":4,"streak":"L2"}`
+
+	tests := []struct {
+		name               string
+		mockDB             getAllStandingsMockDB
+		requestPath        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name: "v1 list with filters",
+			mockDB: getAllStandingsMockDB{
+				expectedQueryVal: db.GetAllTeamsQuery{Tiers: []string{"D"}, Conferences: []string{"C", "E"}},
+				resp:             standings,
+			},
+			requestPath:        "/v1/standings?tier=D&conference=C&conference=E",
+			expectedResp:       fmt.Sprintf(`{"data":[%s,%s],"meta":{"total":2,"page":{"offset":0,"limit":100,"count":2}},"links":{"self":"/v1/standings?tier=D&conference=C&conference=E","first":"/v1/standings?conference=C&conference=E&limit=100&offset=0&tier=D"}}`, standingOne, standingTwo),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "v1 page",
+			mockDB:             getAllStandingsMockDB{resp: standings},
+			requestPath:        "/v1/standings?limit=1",
+			expectedResp:       fmt.Sprintf(`{"data":[%s],"meta":{"total":2,"page":{"offset":0,"limit":1,"count":1}},"links":{"self":"/v1/standings?limit=1","first":"/v1/standings?limit=1&offset=0","next":"/v1/standings?limit=1&offset=1"}}`, standingOne),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Legacy list",
+			mockDB:             getAllStandingsMockDB{resp: standings[1:]},
+			requestPath:        "/standings",
+			expectedResp:       fmt.Sprintf(`{"standings":[%s]}`, standingTwo),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "CSV",
+			mockDB:             getAllStandingsMockDB{resp: standings[:1]},
+			requestPath:        "/v1/standings?format=csv",
+			expectedResp:       "id,name,franchise,tier,conference,division,overall_wins,overall_losses,conference_wins,conference_losses,division_wins,division_losses,series_wins,series_losses,win_percentage,game_differential,games_behind,streak,seed\n1,A,B,D,C,,5,1,3,1,,,2,0,0.833,4,0,W3,1\n",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Invalid team id filter",
+			mockDB:             getAllStandingsMockDB{expectedQueryVal: db.GetAllTeamsQuery{TeamIDs: []string{"abc"}}, err: db.ErrInvalidTypeForQuery},
+			requestPath:        "/v1/standings?id=abc",
+			expectedResp:       `{"error":"Team IDs must be integers"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "List db error",
+			mockDB:             getAllStandingsMockDB{err: errRandom},
+			requestPath:        "/v1/standings",
+			expectedResp:       `{"error":"Failed to fetch standings from db"}`,
+			expectedStatusCode: 500,
+		},
+		{
+			name:               "v1 standing",
+			mockDB:             getAllStandingsMockDB{expectedTeamIDs: []string{"1"}, resp: standings[:1]},
+			requestPath:        "/v1/standings/1",
+			expectedResp:       fmt.Sprintf(`{"data":%s,"meta":{},"links":{"self":"/v1/standings/1"}}`, standingOne),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Legacy standing",
+			mockDB:             getAllStandingsMockDB{expectedTeamIDs: []string{"1"}, resp: standings[:1]},
+			requestPath:        "/standings/1",
+			expectedResp:       standingOne,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Standing not found",
+			mockDB:             getAllStandingsMockDB{expectedTeamIDs: []string{"10"}, resp: []models.Standing{}},
+			requestPath:        "/v1/standings/10",
+			expectedResp:       `{"error":"Standing not found"}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Invalid team id",
+			mockDB:             getAllStandingsMockDB{expectedTeamIDs: []string{"abc"}, err: db.ErrInvalidTypeForQuery},
+			requestPath:        "/v1/standings/abc",
+			expectedResp:       `{"error":"Team ID must be an integer"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Standing db error",
+			mockDB:             getAllStandingsMockDB{expectedTeamIDs: []string{"1"}, err: errRandom},
+			requestPath:        "/v1/standings/1",
+			expectedResp:       `{"error":"Failed to fetch standing from db"}`,
+			expectedStatusCode: 500,
+		},
+	}
+
+	for _, test := range tests {
+		test.mockDB.t = t
+		sHandler := StandingsHandler{DB: test.mockDB}
+		router := mux.NewRouter()
+		for _, v := range []APIVersion{{Name: "v1"}, {Name: LegacyVersion}} {
+			subR := router.PathPrefix(v.Prefix() + "/standings").Subrouter()
+			subR.Use(v.Middleware)
+			sHandler.AddRoutes(subR)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", test.requestPath, nil))
+		require.Equalf(t, test.expectedStatusCode, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, test.expectedResp, recorder.Body.String(), "%q wrong resp", test.name)
+	}
+}
@@ -1,18 +1,22 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
 	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/tabular"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
 	log "github.com/sirupsen/logrus"
 )
 
 // TeamHandler has all routes for team related queries
 type TeamHandler struct {
 	DB db.Datastore
+	// Standings is optional, when set v1 responses and spreadsheet exports include standings
+	Standings db.StandingsStore
 }
 
 // AddRoutes adds all of it's routes to the router
@@ -30,14 +34,105 @@ type teamsListResp struct {
 	Teams []models.Team `json:"teams"`
 }
 
-func (t *TeamHandler) getAllTeams(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		log.Errorf("Invalid URL query string: %s", err)
-		writeError(w, "Invalid query", http.StatusBadRequest)
-		return
+// teamV1 is a team in the v1 API, with its standing if it has one
+type teamV1 struct {
+	models.Team
+	Standing *standingV1 `json:"standing"`
+}
+
+type standingV1 struct {
+	OverallRecord    models.Record  `json:"overall_record"`
+	ConferenceRecord models.Record  `json:"conference_record"`
+	DivisionRecord   *models.Record `json:"division_record,omitempty"`
+	SeriesRecord     models.Record  `json:"series_record"`
+	WinPercentage    float64        `json:"win_percentage"`
+	GameDifferential int            `json:"game_differential"`
+	GamesBehind      float64        `json:"games_behind"`
+	Streak           string         `json:"streak,omitempty"`
+	Seed             *int           `json:"seed,omitempty"`
+}
+
+func makeTeamsV1(teams []models.Team, standings []models.Standing) []teamV1 {
+	standingByID := make(map[string]models.Standing, len(standings))
+	for _, s := range standings {
+		standingByID[s.Team.TeamID] = s
 	}
 
-	query := db.GetAllTeamsQuery{
+	resp := make([]teamV1, len(teams))
+	for i, team := range teams {
+		resp[i] = teamV1{Team: team}
+		if s, ok := standingByID[team.TeamID]; ok {
+			resp[i].Standing = &standingV1{
+				OverallRecord:    s.OverallRecord,
+				ConferenceRecord: s.ConferenceRecord,
+				DivisionRecord:   s.DivisionRecord,
+				SeriesRecord:     s.SeriesRecord,
+				WinPercentage:    s.WinPercentage,
+				GameDifferential: s.GameDifferential,
+				GamesBehind:      s.GamesBehind,
+				Streak:           s.Streak,
+				Seed:             s.Seed,
+			}
+		}
+	}
+	return resp
+}
+
+// teamSerializer makes the JSON bodies of team responses for one API version
+type teamSerializer struct {
+	// withStandings is set if the standings of the teams need to be fetched
+	withStandings bool
+	list          func(teams []models.Team, standings []models.Standing) interface{}
+	single        func(team models.Team, standings []models.Standing) interface{}
+}
+
+var teamSerializers = map[string]teamSerializer{
+	LegacyVersion: {
+		list:   func(teams []models.Team, _ []models.Standing) interface{} { return &teamsListResp{Teams: teams} },
+		single: func(team models.Team, _ []models.Standing) interface{} { return &team },
+	},
+	"v1": {
+		withStandings: true,
+		list: func(teams []models.Team, standings []models.Standing) interface{} {
+			return makeTeamsV1(teams, standings)
+		},
+		single: func(team models.Team, standings []models.Standing) interface{} {
+			return &makeTeamsV1([]models.Team{team}, standings)[0]
+		},
+	},
+}
+
+// teamSerializerForVersion picks the serializer for an API version, versions without their own use the legacy one
+func teamSerializerForVersion(version string) teamSerializer {
+	if s, ok := teamSerializers[version]; ok {
+		return s
+	}
+	return teamSerializers[LegacyVersion]
+}
+
+// getStandings fetches the standings of teams, it returns nil without a standings store
+func (t *TeamHandler) getStandings(ctx context.Context, teams []models.Team) ([]models.Standing, error) {
+	if t.Standings == nil {
+		return nil, nil
+	}
+
+	teamIDs := make([]string, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.TeamID
+	}
+	standings, err := t.Standings.GetStandingsByTeamIDs(ctx, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+	if standings == nil {
+		standings = []models.Standing{}
+	}
+	return standings, nil
+}
+
+// teamsQueryFromForm reads the team filters from a parsed form, each can be given more than once
+func teamsQueryFromForm(r *http.Request) db.GetAllTeamsQuery {
+	return db.GetAllTeamsQuery{
 		TeamIDs:     r.Form["id"],
 		Names:       r.Form["name"],
 		Franchises:  r.Form["franchise"],
@@ -45,8 +140,32 @@ func (t *TeamHandler) getAllTeams(w http.ResponseWriter, r *http.Request) {
 		Tiers:       r.Form["tier"],
 		Divisions:   r.Form["division"],
 	}
+}
+
+func (t *TeamHandler) getAllTeams(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		log.Errorf("Invalid URL query string: %s", err)
+		writeError(w, "Invalid query", http.StatusBadRequest)
+		return
+	}
 
-	teams, err := t.DB.GetAllTeams(query)
+	w.Header().Add("Vary", "Accept")
+	format, err := negotiateFormat(r)
+	if err != nil {
+		writeError(w, "format must be one of json, csv or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	version := VersionFromContext(r.Context())
+	p, ok := parsePage(r)
+	if !ok && usesEnvelope(version) {
+		writeError(w, "offset must be a positive integer and limit an integer from 1 to 500", http.StatusBadRequest)
+		return
+	}
+
+	teams, err := t.DB.GetAllTeams(r.Context(), teamsQueryFromForm(r))
 	if err == db.ErrInvalidTypeForQuery {
 		log.Warn("Invalid query param for team")
 		writeError(w, "Team IDs must be integers", http.StatusBadRequest)
@@ -57,22 +176,47 @@ func (t *TeamHandler) getAllTeams(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	msg, err := json.Marshal(&teamsListResp{Teams: teams})
-	if err != nil {
+	if format != formatJSON {
+		t.exportTeams(w, r, format, teams)
+		return
+	}
+
+	total := len(teams)
+	if usesEnvelope(version) {
+		start, end := p.bounds(total)
+		teams = teams[start:end]
+	}
+
+	serializer := teamSerializerForVersion(version)
+	var standings []models.Standing
+	if serializer.withStandings {
+		standings, err = t.getStandings(r.Context(), teams)
+		if err != nil {
+			log.Errorf("Unable to fetch standings from db: %s", err)
+			writeError(w, "Failed to fetch standings from db", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	body := serializer.list(teams, standings)
+	if usesEnvelope(version) {
+		body = newPageEnvelope(r, body, p, len(teams), total)
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
 		log.Errorf("Unable to marshal teams: %s", err)
 		writeError(w, "Error sending team", http.StatusInternalServerError)
-		return
 	}
-	w.Write(msg)
 }
 
 func (t *TeamHandler) getTeam(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
 	teamID := mux.Vars(r)["id"]
 	query := db.GetAllTeamsQuery{
 		TeamIDs: []string{teamID},
 	}
 
-	teams, err := t.DB.GetAllTeams(query)
+	teams, err := t.DB.GetAllTeams(r.Context(), query)
 	if err == db.ErrInvalidTypeForQuery {
 		log.Warnf("Invalid team id: %s", teamID)
 		writeError(w, "Team ID must be an integer", http.StatusBadRequest)
@@ -88,11 +232,40 @@ func (t *TeamHandler) getTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	msg, err := json.Marshal(&teams[0])
-	if err != nil {
+	version := VersionFromContext(r.Context())
+	serializer := teamSerializerForVersion(version)
+	var standings []models.Standing
+	if serializer.withStandings {
+		standings, err = t.getStandings(r.Context(), teams[:1])
+		if err != nil {
+			log.Errorf("Unable to fetch standings from db: %s", err)
+			writeError(w, "Failed to fetch standings from db", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	body := serializer.single(teams[0], standings)
+	if usesEnvelope(version) {
+		body = newItemEnvelope(r, body)
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
 		log.Errorf("Unable to marshal teams: %s", err)
 		writeError(w, "Error sending team", http.StatusInternalServerError)
+	}
+}
+
+// exportTeams writes teams as a spreadsheet, with their standings if a standings store is set
+func (t *TeamHandler) exportTeams(w http.ResponseWriter, r *http.Request, format string, teams []models.Team) {
+	log := logging.FromContext(r.Context())
+
+	standings, err := t.getStandings(r.Context(), teams)
+	if err != nil {
+		log.Errorf("Unable to fetch standings from db: %s", err)
+		writeError(w, "Failed to fetch standings from db", http.StatusInternalServerError)
 		return
 	}
-	w.Write(msg)
+
+	if err := writeTable(w, format, tabular.Teams(teams, standings)); err != nil {
+		log.Errorf("Unable to write teams as %s: %s", format, err)
+	}
 }
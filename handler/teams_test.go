@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -85,7 +86,7 @@ type getAllTeamsMockDB struct {
 	err  error
 }
 
-func (d getAllTeamsMockDB) GetAllTeams(query db.GetAllTeamsQuery) ([]models.Team, error) {
+func (d getAllTeamsMockDB) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
 	require.Equal(d.t, d.expectedQueryVal, query)
 
 	return d.resp, d.err
@@ -283,3 +284,90 @@ func Test_getTeam(t *testing.T) {
 		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
 	}
 }
+
+func Test_TeamHandler_v1(t *testing.T) {
+	teams := []models.Team{
+		{TeamID: "1", Name: "A", Franchise: "B", Conference: "C", Tier: "D", Division: strPointer("E")},
+		{TeamID: "2", Name: "F", Franchise: "B", Conference: "C", Tier: "D"},
+	}
+	standings := []models.Standing{
+		{
+			Team: teams[0], OverallRecord: models.Record{Wins: 5, Losses: 1}, ConferenceRecord: models.Record{Wins: 3, Losses: 1}, DivisionRecord: &models.Record{Wins: 2, Losses: 0},
+			SeriesRecord: models.Record{Wins: 2, Losses: 0}, WinPercentage: 0.833, GameDifferential: 4, GamesBehind: 0, Streak: "W3", Seed: intPointer(1),
+		},
+	}
+	teamOneV1 := `{"id":"1","name":"A","franchise":"B","tier":"D","conference":"C","division":"E","standing":{"overall_record":{"wins":5,"losses":1},"conference_record":{"wins":3,"losses":1},"division_record":{"wins":2,"losses":0},` +
+		`"series_record":{"wins":2,"losses":0},"win_percentage":0.833,"game_differential":4,"games_behind":0,"streak":"W3","seed":1}}`
+	teamTwoV1 := `{"id":"2","name":"F","franchise":"B","tier":"D","conference":"C","standing":null}`
+
+	tests := []struct {
+		name               string
+		mockDB             db.Datastore
+		standings          db.StandingsStore
+		requestPath        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "v1 list has standings",
+			mockDB:             getAllTeamsMockDB{t: t, resp: teams},
+			standings:          standingsMock{resp: standings},
+			requestPath:        "/v1/team",
+			expectedResp:       fmt.Sprintf(`{"data":[%s,%s],"meta":{"total":2,"page":{"offset":0,"limit":100,"count":2}},"links":{"self":"/v1/team","first":"/v1/team?limit=100&offset=0"}}`, teamOneV1, teamTwoV1),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "v1 team has standing",
+			mockDB:             getAllTeamsMockDB{t: t, expectedQueryVal: db.GetAllTeamsQuery{TeamIDs: []string{"1"}}, resp: teams[:1]},
+			standings:          standingsMock{resp: standings},
+			requestPath:        "/v1/team/1",
+			expectedResp:       fmt.Sprintf(`{"data":%s,"meta":{},"links":{"self":"/v1/team/1"}}`, teamOneV1),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "v1 page without a standings store",
+			mockDB:             getAllTeamsMockDB{t: t, resp: teams},
+			requestPath:        "/v1/team?limit=1&offset=1",
+			expectedResp:       fmt.Sprintf(`{"data":[%s],"meta":{"total":2,"page":{"offset":1,"limit":1,"count":1}},"links":{"self":"/v1/team?limit=1&offset=1","first":"/v1/team?limit=1&offset=0","prev":"/v1/team?limit=1&offset=0"}}`, teamTwoV1),
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "v1 bad page",
+			mockDB:             getAllTeamsMockDB{t: t, resp: teams},
+			requestPath:        "/v1/team?limit=0",
+			expectedResp:       `{"error":"offset must be a positive integer and limit an integer from 1 to 500"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "v1 standings error",
+			mockDB:             getAllTeamsMockDB{t: t, resp: teams},
+			standings:          standingsMock{err: errRandom},
+			requestPath:        "/v1/team",
+			expectedResp:       `{"error":"Failed to fetch standings from db"}`,
+			expectedStatusCode: 500,
+		},
+		{
+			name:               "Legacy list is unchanged",
+			mockDB:             getAllTeamsMockDB{t: t, resp: teams[1:]},
+			standings:          standingsMock{resp: standings},
+			requestPath:        "/team",
+			expectedResp:       `{"teams":[{"id":"2","name":"F","franchise":"B","tier":"D","conference":"C"}]}`,
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, test := range tests {
+		tHandler := TeamHandler{DB: test.mockDB, Standings: test.standings}
+		router := mux.NewRouter()
+		for _, v := range []APIVersion{{Name: "v1"}, {Name: LegacyVersion}} {
+			subR := router.PathPrefix(v.Prefix() + "/team").Subrouter()
+			subR.Use(v.Middleware)
+			tHandler.AddRoutes(subR)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", test.requestPath, nil))
+		require.Equalf(t, test.expectedStatusCode, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, test.expectedResp, recorder.Body.String(), "%q wrong resp", test.name)
+	}
+}
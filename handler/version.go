@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LegacyVersion is the name of the unprefixed routes that were served before versioning
+const LegacyVersion = ""
+
+// APIVersion is a version of the API that routes can be mounted under
+type APIVersion struct {
+	// Name is the path prefix of the version, without slashes. LegacyVersion mounts routes at the root.
+	Name string
+	// Deprecation is when the version was deprecated, zero if it isn't
+	Deprecation time.Time
+	// Sunset is when the version will stop being served, zero if it isn't planned
+	Sunset time.Time
+	// Successor is the name of the version clients should move to
+	Successor string
+}
+
+// Prefix is the path prefix routes of this version are mounted under
+func (v APIVersion) Prefix() string {
+	if v.Name == LegacyVersion {
+		return ""
+	}
+	return "/" + v.Name
+}
+
+// Deprecated checks if clients should stop using this version
+func (v APIVersion) Deprecated() bool {
+	return !v.Deprecation.IsZero()
+}
+
+type versionCtxKey struct{}
+
+// VersionFromContext gets the name of the API version a request was made to
+func VersionFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(versionCtxKey{}).(string); ok {
+		return name
+	}
+	return LegacyVersion
+}
+
+// successorPath swaps the version prefix of path for the successor's
+func (v APIVersion) successorPath(path string) string {
+	path = strings.TrimPrefix(path, v.Prefix())
+	return APIVersion{Name: v.Successor}.Prefix() + path
+}
+
+// Middleware tags requests with the version, and warns clients of deprecated versions
+// with Deprecation (RFC 9745), Sunset (RFC 8594) and successor-version Link headers
+func (v APIVersion) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v.Deprecated() {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", v.Deprecation.Unix()))
+			if !v.Sunset.IsZero() {
+				w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if v.Successor != "" {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, v.successorPath(r.URL.Path)))
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionCtxKey{}, v.Name)))
+	})
+}
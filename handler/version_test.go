@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APIVersion_Prefix(t *testing.T) {
+	require.Equal(t, "/v1", APIVersion{Name: "v1"}.Prefix())
+	require.Equal(t, "", APIVersion{Name: LegacyVersion}.Prefix())
+}
+
+func Test_APIVersion_Middleware(t *testing.T) {
+	deprecation := time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		version             APIVersion
+		requestPath         string
+		expectedVersion     string
+		expectedDeprecation string
+		expectedSunset      string
+		expectedLink        string
+	}{
+		{
+			name:            "Current version",
+			version:         APIVersion{Name: "v1"},
+			requestPath:     "/v1/team/1",
+			expectedVersion: "v1",
+		},
+		{
+			name:                "Deprecated legacy version",
+			version:             APIVersion{Name: LegacyVersion, Deprecation: deprecation, Sunset: sunset, Successor: "v1"},
+			requestPath:         "/team/1",
+			expectedVersion:     LegacyVersion,
+			expectedDeprecation: "@1604188800",
+			expectedSunset:      "Sat, 01 May 2021 00:00:00 GMT",
+			expectedLink:        `</v1/team/1>; rel="successor-version"`,
+		},
+		{
+			name:                "Deprecated without sunset or successor",
+			version:             APIVersion{Name: "v1", Deprecation: deprecation},
+			requestPath:         "/v1/team",
+			expectedVersion:     "v1",
+			expectedDeprecation: "@1604188800",
+		},
+		{
+			name:                "Deprecated versioned prefix",
+			version:             APIVersion{Name: "v1", Deprecation: deprecation, Successor: "v2"},
+			requestPath:         "/v1/team",
+			expectedVersion:     "v1",
+			expectedDeprecation: "@1604188800",
+			expectedLink:        `</v2/team>; rel="successor-version"`,
+		},
+	}
+
+	for _, test := range tests {
+		var actualVersion string
+		handler := test.version.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actualVersion = VersionFromContext(r.Context())
+		}))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", test.requestPath, nil))
+
+		require.Equalf(t, test.expectedVersion, actualVersion, "%q wrong version", test.name)
+		require.Equalf(t, test.expectedDeprecation, recorder.Header().Get("Deprecation"), "%q wrong deprecation", test.name)
+		require.Equalf(t, test.expectedSunset, recorder.Header().Get("Sunset"), "%q wrong sunset", test.name)
+		require.Equalf(t, test.expectedLink, recorder.Header().Get("Link"), "%q wrong link", test.name)
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// webhookDeliveriesLimit is how many of the most recent deliveries are returned
+const webhookDeliveriesLimit = 100
+
+// WebhookHandler has all routes for managing webhook subscriptions
+type WebhookHandler struct {
+	DB db.WebhookStore
+}
+
+// AddRoutes adds all of it's routes to the router
+func (h *WebhookHandler) AddRoutes(router *mux.Router) {
+	if h.DB == nil {
+		log.Fatal("WebhookHandler.DB is nil!")
+	}
+
+	router.HandleFunc("", h.getSubscriptions).Methods("GET")
+	router.HandleFunc("/", h.getSubscriptions).Methods("GET")
+	router.HandleFunc("", h.createSubscription).Methods("POST")
+	router.HandleFunc("/", h.createSubscription).Methods("POST")
+	router.HandleFunc("/{id}", h.deleteSubscription).Methods("DELETE")
+	router.HandleFunc("/{id}/deliveries", h.getDeliveries).Methods("GET")
+}
+
+type webhookSubscriptionsResp struct {
+	Subscriptions []models.WebhookSubscription `json:"subscriptions"`
+}
+
+type createWebhookReq struct {
+	URL        string             `json:"url"`
+	EventTypes []models.EventType `json:"event_types"`
+}
+
+type webhookDeliveriesResp struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+func validEventType(eventType models.EventType) bool {
+	for _, t := range models.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *WebhookHandler) getSubscriptions(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	version := VersionFromContext(r.Context())
+	p, ok := parsePage(r)
+	if !ok && usesEnvelope(version) {
+		writeError(w, "offset must be a positive integer and limit an integer from 1 to 500", http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.DB.GetWebhookSubscriptions(r.Context())
+	if err != nil {
+		log.Errorf("Unable to fetch webhook subscriptions from db: %s", err)
+		writeError(w, "Failed to fetch webhook subscriptions from db", http.StatusInternalServerError)
+		return
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+
+	var body interface{} = &webhookSubscriptionsResp{Subscriptions: subs}
+	if usesEnvelope(version) {
+		total := len(subs)
+		start, end := p.bounds(total)
+		body = newPageEnvelope(r, subs[start:end], p, end-start, total)
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
+		log.Errorf("Unable to marshal webhook subscriptions: %s", err)
+		writeError(w, "Error sending webhook subscriptions", http.StatusInternalServerError)
+	}
+}
+
+func (h *WebhookHandler) createSubscription(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	req := createWebhookReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeError(w, "url must be an absolute http(s) url", http.StatusBadRequest)
+		return
+	}
+	for _, t := range req.EventTypes {
+		if !validEventType(t) {
+			writeError(w, "Unknown event type: "+string(t), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.EventTypes == nil {
+		req.EventTypes = []models.EventType{}
+	}
+
+	sub, err := h.DB.CreateWebhookSubscription(r.Context(), req.URL, req.EventTypes)
+	if err != nil {
+		log.Errorf("Unable to create webhook subscription: %s", err)
+		writeError(w, "Failed to create webhook subscription", http.StatusInternalServerError)
+		return
+	}
+
+	var body interface{} = &sub
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newItemEnvelope(r, &sub)
+	}
+	if err := writeJSON(w, r, http.StatusCreated, body); err != nil {
+		log.Errorf("Unable to marshal webhook subscription: %s", err)
+		writeError(w, "Error sending webhook subscription", http.StatusInternalServerError)
+	}
+}
+
+func (h *WebhookHandler) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Webhook ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	err = h.DB.DeleteWebhookSubscription(r.Context(), id)
+	if err == db.ErrWebhookNotFound {
+		writeError(w, "Webhook not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Errorf("Unable to delete webhook subscription: %s", err)
+		writeError(w, "Failed to delete webhook subscription", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) getDeliveries(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "Webhook ID must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.DB.GetWebhookDeliveries(r.Context(), id, webhookDeliveriesLimit)
+	if err != nil {
+		log.Errorf("Unable to fetch webhook deliveries from db: %s", err)
+		writeError(w, "Failed to fetch webhook deliveries from db", http.StatusInternalServerError)
+		return
+	}
+
+	var body interface{} = &webhookDeliveriesResp{Deliveries: deliveries}
+	if usesEnvelope(VersionFromContext(r.Context())) {
+		body = newListEnvelope(r, deliveries, webhookDeliveriesLimit, len(deliveries))
+	}
+	if err := writeJSON(w, r, http.StatusOK, body); err != nil {
+		log.Errorf("Unable to marshal webhook deliveries: %s", err)
+		writeError(w, "Error sending webhook deliveries", http.StatusInternalServerError)
+	}
+}
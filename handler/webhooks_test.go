@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookStoreMock struct {
+	db.WebhookStore
+
+	t *testing.T
+
+	subs    []models.WebhookSubscription
+	created models.WebhookSubscription
+	err     error
+
+	expectedID int
+	deliveries []models.WebhookDelivery
+}
+
+func (m webhookStoreMock) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return m.subs, m.err
+}
+
+func (m webhookStoreMock) CreateWebhookSubscription(ctx context.Context, url string, eventTypes []models.EventType) (models.WebhookSubscription, error) {
+	require.Equal(m.t, m.created.URL, url)
+	require.Equal(m.t, m.created.EventTypes, eventTypes)
+	return m.created, m.err
+}
+
+func (m webhookStoreMock) DeleteWebhookSubscription(ctx context.Context, id int) error {
+	require.Equal(m.t, m.expectedID, id)
+	return m.err
+}
+
+func (m webhookStoreMock) GetWebhookDeliveries(ctx context.Context, subscriptionID, limit int) ([]models.WebhookDelivery, error) {
+	require.Equal(m.t, m.expectedID, subscriptionID)
+	return m.deliveries, m.err
+}
+
+func Test_WebhookHandler_AddRoutes_NilDB(t *testing.T) {
+	origExitFunc := log.StandardLogger().ExitFunc
+	defer func() { log.StandardLogger().ExitFunc = origExitFunc }()
+	var fatal bool
+	log.StandardLogger().ExitFunc = func(int) { fatal = true }
+
+	wHandler := WebhookHandler{}
+	wHandler.AddRoutes(mux.NewRouter())
+
+	require.Equal(t, true, fatal)
+}
+
+func Test_WebhookHandler(t *testing.T) {
+	createdAt := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	sub := models.WebhookSubscription{ID: 1, URL: "https://bot.example.com/hook", Secret: "shh", EventTypes: []models.EventType{models.EventTeamAdded}, CreatedAt: createdAt}
+
+	tests := []struct {
+		name               string
+		mockDB             webhookStoreMock
+		requestPath        string
+		requestMethod      string
+		requestBody        string
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "List hides secrets",
+			mockDB:             webhookStoreMock{subs: []models.WebhookSubscription{sub}},
+			requestPath:        "/",
+			requestMethod:      "GET",
+			expectedResp:       `{"subscriptions":[{"id":1,"url":"https://bot.example.com/hook","event_types":["team.added"],"created_at":"2020-09-01T12:00:00Z"}]}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "List db error",
+			mockDB:             webhookStoreMock{err: errRandom},
+			requestPath:        "/",
+			requestMethod:      "GET",
+			expectedResp:       `{"error":"Failed to fetch webhook subscriptions from db"}`,
+			expectedStatusCode: 500,
+		},
+		{
+			name:               "Create",
+			mockDB:             webhookStoreMock{created: sub},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"url":"https://bot.example.com/hook","event_types":["team.added"]}`,
+			expectedResp:       `{"id":1,"url":"https://bot.example.com/hook","secret":"shh","event_types":["team.added"],"created_at":"2020-09-01T12:00:00Z"}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "Create bad url",
+			mockDB:             webhookStoreMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"url":"ftp://bot.example.com"}`,
+			expectedResp:       `{"error":"url must be an absolute http(s) url"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Create bad event type",
+			mockDB:             webhookStoreMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{"url":"https://bot.example.com","event_types":["team.exploded"]}`,
+			expectedResp:       `{"error":"Unknown event type: team.exploded"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Create bad body",
+			mockDB:             webhookStoreMock{},
+			requestPath:        "/",
+			requestMethod:      "POST",
+			requestBody:        `{`,
+			expectedResp:       `{"error":"Invalid request body"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Delete",
+			mockDB:             webhookStoreMock{expectedID: 1},
+			requestPath:        "/1",
+			requestMethod:      "DELETE",
+			expectedResp:       "",
+			expectedStatusCode: 204,
+		},
+		{
+			name:               "Delete not found",
+			mockDB:             webhookStoreMock{expectedID: 2, err: db.ErrWebhookNotFound},
+			requestPath:        "/2",
+			requestMethod:      "DELETE",
+			expectedResp:       `{"error":"Webhook not found"}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Delete bad id",
+			mockDB:             webhookStoreMock{},
+			requestPath:        "/abc",
+			requestMethod:      "DELETE",
+			expectedResp:       `{"error":"Webhook ID must be an integer"}`,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "Deliveries",
+			mockDB:             webhookStoreMock{expectedID: 1, deliveries: []models.WebhookDelivery{{ID: 5, SubscriptionID: 1, EventType: models.EventTeamAdded, Attempt: 1, StatusCode: intPointer(200), Success: true, DeliveredAt: createdAt}}},
+			requestPath:        "/1/deliveries",
+			requestMethod:      "GET",
+			expectedResp:       `{"deliveries":[{"id":5,"subscription_id":1,"event_type":"team.added","attempt":1,"status_code":200,"success":true,"delivered_at":"2020-09-01T12:00:00Z"}]}`,
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, test := range tests {
+		test.mockDB.t = t
+		wHandler := WebhookHandler{DB: test.mockDB}
+		router := mux.NewRouter()
+		wHandler.AddRoutes(router)
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		url := fmt.Sprintf("%s%s", server.URL, test.requestPath)
+		req, _ := http.NewRequest(test.requestMethod, url, strings.NewReader(test.requestBody))
+		actual, err := http.DefaultClient.Do(req)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		t.Cleanup(func() { actual.Body.Close() })
+		require.Equalf(t, test.expectedStatusCode, actual.StatusCode, "%q wrong status code", test.name)
+		body, err := ioutil.ReadAll(actual.Body)
+		require.NoErrorf(t, err, "%q should not have errored", test.name)
+		require.Equalf(t, test.expectedResp, string(body), "%q wrong resp", test.name)
+	}
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader is the header used to receive and send request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID accepted from a client
+const maxRequestIDLength = 128
+
+type ctxKey int
+
+const loggerKey ctxKey = 0
+
+// Configure sets the format and level of the standard logger.
+// format can be "json" or "text", level is any level logrus can parse.
+func Configure(format, level string) error {
+	switch strings.ToLower(format) {
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	case "text", "":
+		log.SetFormatter(&log.TextFormatter{})
+	default:
+		return fmt.Errorf("unknown log format: %s", format)
+	}
+
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(lvl)
+
+	return nil
+}
+
+// WithLogger returns a copy of ctx carrying the given log entry
+func WithLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey, entry)
+}
+
+// FromContext gets the request scoped log entry from ctx, falling back to the standard logger
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(loggerKey).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID checks a client's request ID is short and only has characters that are safe to echo and log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlnum && !strings.ContainsRune("-_.:", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder keeps track of the status code and size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(b)
+	s.size += n
+	return n, err
+}
+
+// Middleware assigns every request an ID, or reuses the one sent by the client if it's valid,
+// adds a log entry tagged with it to the request context, and logs the request once it's served
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := log.WithField("request_id", requestID)
+		recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(WithLogger(r.Context(), entry)))
+
+		entry.WithFields(log.Fields{
+			"method":      r.Method,
+			"path":        r.URL.RequestURI(),
+			"remote_addr": r.RemoteAddr,
+			"status":      recorder.code,
+			"size":        recorder.size,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"user_agent":  r.UserAgent(),
+		}).Info("Handled request")
+	})
+}
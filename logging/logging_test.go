@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Configure(t *testing.T) {
+	origFormatter := log.StandardLogger().Formatter
+	origLevel := log.GetLevel()
+	t.Cleanup(func() {
+		log.SetFormatter(origFormatter)
+		log.SetLevel(origLevel)
+	})
+
+	require.NoError(t, Configure("json", "debug"))
+	require.IsType(t, &log.JSONFormatter{}, log.StandardLogger().Formatter)
+	require.Equal(t, log.DebugLevel, log.GetLevel())
+
+	require.NoError(t, Configure("", "warn"))
+	require.IsType(t, &log.TextFormatter{}, log.StandardLogger().Formatter)
+	require.Equal(t, log.WarnLevel, log.GetLevel())
+
+	require.EqualError(t, Configure("xml", "info"), "unknown log format: xml")
+	require.Error(t, Configure("json", "loud"))
+}
+
+func Test_FromContext(t *testing.T) {
+	entry := log.WithField("request_id", "abc")
+	ctx := WithLogger(context.Background(), entry)
+	require.Equal(t, entry, FromContext(ctx))
+
+	require.NotNil(t, FromContext(context.Background()))
+}
+
+func Test_Middleware(t *testing.T) {
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	var handlerRequestID interface{}
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerRequestID = FromContext(r.Context()).Data["request_id"]
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name           string
+		sentRequestID  string
+		checkRequestID func(string)
+	}{
+		{
+			name:          "Propagates request id",
+			sentRequestID: "my-request-id",
+			checkRequestID: func(id string) {
+				require.Equal(t, "my-request-id", id)
+			},
+		},
+		{
+			name:          "Generates request id",
+			sentRequestID: "",
+			checkRequestID: func(id string) {
+				require.Len(t, id, 32)
+			},
+		},
+		{
+			name:          "Replaces request id with unsafe characters",
+			sentRequestID: "abc\" status=500",
+			checkRequestID: func(id string) {
+				require.Len(t, id, 32)
+				require.NotContains(t, id, "abc")
+			},
+		},
+		{
+			name:          "Replaces too long request id",
+			sentRequestID: strings.Repeat("a", 129),
+			checkRequestID: func(id string) {
+				require.Len(t, id, 32)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		hook.Reset()
+		req := httptest.NewRequest("GET", "/team?id=1", nil)
+		if test.sentRequestID != "" {
+			req.Header.Set(RequestIDHeader, test.sentRequestID)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		respID := recorder.Header().Get(RequestIDHeader)
+		test.checkRequestID(respID)
+		require.Equalf(t, respID, handlerRequestID, "%q handler got wrong request id", test.name)
+
+		entry := hook.LastEntry()
+		require.NotNilf(t, entry, "%q should have logged", test.name)
+		require.Equalf(t, respID, entry.Data["request_id"], "%q wrong logged request id", test.name)
+		require.Equalf(t, http.StatusTeapot, entry.Data["status"], "%q wrong logged status", test.name)
+		require.Equalf(t, "/team?id=1", entry.Data["path"], "%q wrong logged path", test.name)
+	}
+}
@@ -5,15 +5,50 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	gorillaHandlers "github.com/gorilla/handlers"
+	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
-	"github.com/mellena1/RSC-Spreadsheet-API/data/sheets"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/events"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/graph"
 	"github.com/mellena1/RSC-Spreadsheet-API/handler"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/mellena1/RSC-Spreadsheet-API/metrics"
+	"github.com/mellena1/RSC-Spreadsheet-API/webhooks"
 	log "github.com/sirupsen/logrus"
 )
 
+// version is the build version of the API, set with -ldflags "-X main.version=..."
+var version = "dev"
+
+// apiVersions are the versions API routes are mounted under
+var apiVersions = []handler.APIVersion{{Name: "v1"}}
+
+// teamVersions are the versions the team routes are mounted under, they're also served unversioned
+// since they were the only routes before versioning
+func teamVersions() []handler.APIVersion {
+	return append(apiVersions, legacyAPIVersion())
+}
+
+// legacyAPIVersion is the unversioned routes, they're deprecated once LEGACY_API_DEPRECATION_DATE is set,
+// with LEGACY_API_SUNSET_DATE announcing when they'll be removed
+func legacyAPIVersion() handler.APIVersion {
+	v := handler.APIVersion{
+		Name:        handler.LegacyVersion,
+		Deprecation: getEnvDateOrDefault("LEGACY_API_DEPRECATION_DATE", time.Time{}),
+		Sunset:      getEnvDateOrDefault("LEGACY_API_SUNSET_DATE", time.Time{}),
+		Successor:   "v1",
+	}
+	if !v.Sunset.IsZero() && !v.Sunset.After(v.Deprecation) {
+		log.Fatal("LEGACY_API_SUNSET_DATE must be after LEGACY_API_DEPRECATION_DATE")
+	}
+	return v
+}
+
 // RouterCreator a handler that can return a gorilla mux router for path prefixes
 type RouterCreator interface {
 	AddRoutes(*mux.Router)
@@ -34,71 +69,336 @@ func getEnvOrDefault(key, _default string) string {
 	return _default
 }
 
-func main() {
-	mydb := makeDB()
-	defer mydb.Close()
+func getEnvIntOrDefault(key string, _default int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return _default
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("Env var %s must be an integer: %v", key, err)
+	}
+	return i
+}
 
-	router := makeHTTPRouter(mydb)
-	log.Info("Serving on :8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+func getEnvBoolOrDefault(key string, _default bool) bool {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return _default
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("Env var %s must be a boolean: %v", key, err)
+	}
+	return b
+}
 
-	if err := mydb.Close(); err != nil {
-		log.Fatalf("Error closing db: %v\n", err)
+// getEnvDateOrDefault reads a date like 2006-01-02, in UTC
+func getEnvDateOrDefault(key string, _default time.Time) time.Time {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return _default
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		log.Fatalf("Env var %s must be a date like 2006-01-02: %v", key, err)
 	}
+	return t
 }
 
-func makeDB() *db.DB {
-	teamStandings, err := sheets.NewTeamStandingsSheet(
-		context.TODO(),
-		"1l99BZtpFdVB8M6xB7VJii4aAj5O33u6HUvZLGfwHB0k",
-		"All Teams Data",
-		fatalIfMissingEnvVar("RSC_SHEETS_API_TOKEN"),
-	)
+func getEnvListOrDefault(key string, _default []string) []string {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return _default
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func main() {
+	err := logging.Configure(getEnvOrDefault("LOG_FORMAT", "text"), getEnvOrDefault("LOG_LEVEL", "info"))
 	if err != nil {
-		log.Fatalf("Error making TeamStandingsSheet: %v\n", err)
+		log.Fatalf("Error configuring logging: %v\n", err)
+	}
+
+	if err := runCommand(os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
 	}
+}
+
+// serve syncs the db and serves the API until the server fails
+func serve() error {
+	mydb := makeDB()
+	defer mydb.Close()
+	mydb.StartSchedules(context.Background())
+
+	dispatcher := webhooks.NewDispatcher(mydb, &http.Client{Timeout: 10 * time.Second}, 5, 5*time.Second)
+	dispatcher.Start(context.Background(), 2)
+	mydb.OnEvents(dispatcher.Dispatch)
+
+	broker := events.NewBroker()
+	mydb.OnEvents(broker.Publish)
 
-	dbStr := fmt.Sprintf(
+	router := makeHTTPRouter(mydb, broker, teamVersions())
+	log.Info("Serving on :8080")
+	return http.ListenAndServe(":8080", wrapHTTPRouter(router))
+}
+
+func dbConnStr() string {
+	return fmt.Sprintf(
 		"postgres://%s:%s@%s?sslmode=disable",
 		getEnvOrDefault("DB_USER", "postgres"),
 		getEnvOrDefault("DB_PASS", "password"),
 		fatalIfMissingEnvVar("DB_HOST"),
 	)
-	mydb, err := db.NewDB(dbStr, teamStandings)
+}
+
+// makeDB opens the db and syncs it. If the sync fails the API still starts, serving the data from earlier syncs.
+func makeDB() *db.DB {
+	mydb, err := db.OpenDB(dbConnStr(), makeSources())
 	if err != nil {
 		log.Fatalf("Error making db: %v\n", err)
 	}
 
+	if _, err := mydb.Sync(context.Background(), ""); err != nil {
+		if lastSync := mydb.LastSync(); lastSync.IsZero() {
+			log.Errorf("Initial sync failed and the db has never been synced, serving no data until a sync succeeds: %v", err)
+		} else {
+			log.Errorf("Initial sync failed, serving data last synced at %s: %v", lastSync.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := metrics.RegisterDataAge(mydb.LastSync); err != nil {
+		log.Fatalf("Error registering data age metric: %v\n", err)
+	}
+
 	return mydb
 }
 
-func makeHTTPRouter(_db *db.DB) http.Handler {
+// wrapHTTPRouter adds the middlewares that have to run before routing. Requests are logged here
+// so ones that don't match a route are logged too, and CORS preflight requests don't match
+// any route's methods so they'd 405 otherwise.
+func wrapHTTPRouter(router *mux.Router) http.Handler {
+	return logging.Middleware(metrics.HTTPMiddleware(router)(withCORS(handler.Compress(router))))
+}
+
+// withCORS allows cross origin requests from CORS_ALLOWED_ORIGINS
+func withCORS(h http.Handler) http.Handler {
+	origins := getEnvListOrDefault("CORS_ALLOWED_ORIGINS", nil)
+	if len(origins) == 0 {
+		log.Info("CORS_ALLOWED_ORIGINS not set, cross origin requests are disabled")
+		return h
+	}
+
+	corsOptions := []handlers.CORSOption{
+		handlers.AllowedOrigins(origins),
+		handlers.AllowedMethods(getEnvListOrDefault("CORS_ALLOWED_METHODS", []string{"GET", "HEAD", "POST", "DELETE"})),
+		handlers.AllowedHeaders(getEnvListOrDefault("CORS_ALLOWED_HEADERS", []string{
+			"Content-Type", "Authorization", handler.APIKeyHeader, logging.RequestIDHeader,
+			"Last-Event-ID", "If-None-Match", "If-Modified-Since",
+		})),
+		handlers.ExposedHeaders([]string{
+			"ETag", "Last-Modified", "Retry-After", logging.RequestIDHeader,
+			"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+			"Deprecation", "Sunset", "Link",
+		}),
+		handlers.MaxAge(getEnvIntOrDefault("CORS_MAX_AGE_SECONDS", 600)),
+	}
+	if getEnvBoolOrDefault("CORS_ALLOW_CREDENTIALS", false) {
+		corsOptions = append(corsOptions, handlers.AllowCredentials())
+	}
+
+	return handlers.CORS(corsOptions...)(h)
+}
+
+func makeHTTPRouter(_db *db.DB, broker *events.Broker, teamVersions []handler.APIVersion) *mux.Router {
+	responseMeta := handler.ResponseMeta{
+		LastSync: _db.LastChange,
+		Season:   getEnvOrDefault("SEASON", ""),
+	}
+
 	router := mux.NewRouter()
+	router.Use(responseMeta.Middleware)
 
-	childRouters := getChildRouters(_db)
+	childRouters := getChildRouters(_db, broker, teamVersions)
 	for _, c := range childRouters {
-		subR := router.PathPrefix(c.PathPrefix).Subrouter()
-		c.Child.AddRoutes(subR)
+		if len(c.Versions) == 0 {
+			subR := router.PathPrefix(c.PathPrefix).Subrouter()
+			subR.Use(c.Middlewares...)
+			c.Child.AddRoutes(subR)
+			continue
+		}
+
+		for _, v := range c.Versions {
+			subR := router.PathPrefix(v.Prefix() + c.PathPrefix).Subrouter()
+			subR.Use(v.Middleware)
+			subR.Use(c.Middlewares...)
+			c.Child.AddRoutes(subR)
+		}
 	}
 
-	loggingRouterHandler := gorillaHandlers.LoggingHandler(os.Stdout, router)
+	return router
+}
+
+// graphStore is what GraphQL queries read from, teams and standings come through the same caches as the REST routes
+type graphStore struct {
+	db.Datastore
+	*db.CachedStandingsStore
+	players interface {
+		GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error)
+	}
+}
 
-	return loggingRouterHandler
+// GetPlayersByTeamIDs gets players from the db, they aren't cached
+func (s graphStore) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	return s.players.GetPlayersByTeamIDs(ctx, teamIDs)
 }
 
 // ChildRouter holds a child handler that can be used for path prefixes
 type ChildRouter struct {
-	PathPrefix string
-	Child      RouterCreator
+	PathPrefix  string
+	Child       RouterCreator
+	Middlewares []mux.MiddlewareFunc
+	// Versions mounts the child under each API version's prefix, leave empty for unversioned routes like health checks.
+	// The child reads the version with handler.VersionFromContext to pick how to serialize responses.
+	Versions []handler.APIVersion
 }
 
-func getChildRouters(_db *db.DB) []ChildRouter {
-	return []ChildRouter{
+func getChildRouters(_db *db.DB, broker *events.Broker, teamVersions []handler.APIVersion) []ChildRouter {
+	cachedDB := db.NewCachedDatastore(_db, getEnvIntOrDefault("CACHE_MAX_ENTRIES", 1000))
+	_db.OnTablesCommit([]string{"team"}, cachedDB.Clear)
+	cachedStandings := db.NewCachedStandingsStore(_db, getEnvIntOrDefault("CACHE_MAX_ENTRIES", 1000))
+	_db.OnTablesCommit([]string{"team", "team_standing"}, cachedStandings.Clear)
+
+	requireKey := getEnvBoolOrDefault("REQUIRE_API_KEY", false)
+	authenticator := handler.NewAuthenticator(
+		_db,
+		requireKey,
+		getEnvIntOrDefault("RATE_LIMIT_ANONYMOUS", 30),
+		getEnvIntOrDefault("RATE_LIMIT_KEYED", 300),
+	)
+	authenticator.InvalidKeyLimit = getEnvIntOrDefault("RATE_LIMIT_INVALID_KEYS", authenticator.InvalidKeyLimit)
+	if authenticator.InvalidKeyLimit < 1 {
+		// 0 would block every request with a key
+		log.Fatal("RATE_LIMIT_INVALID_KEYS must be at least 1")
+	}
+	// the anonymous limit is per ip address, behind a load balancer set its addresses so clients aren't all limited together
+	trustedProxies, err := handler.ParseTrustedProxies(getEnvListOrDefault("TRUSTED_PROXIES", nil))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	authenticator.TrustedProxies = trustedProxies
+	// responses can be cached until the next scheduled sync, CACHE_MAX_AGE_SECONDS is used when sources only sync on demand
+	maxAge := _db.SyncInterval()
+	if maxAge == 0 {
+		maxAge = time.Duration(getEnvIntOrDefault("CACHE_MAX_AGE_SECONDS", 60)) * time.Second
+	}
+	httpCache := &handler.HTTPCache{
+		LastSync: _db.LastChange,
+		MaxAge:   maxAge,
+		Private:  requireKey,
+	}
+
+	store := graphStore{Datastore: cachedDB, CachedStandingsStore: cachedStandings, players: _db}
+	schema, err := graph.NewSchema(store, getEnvIntOrDefault("GRAPHQL_MAX_DEPTH", 6))
+	if err != nil {
+		log.Fatalf("Failed to parse GraphQL schema: %v", err)
+	}
+
+	childRouters := []ChildRouter{
+		{
+			PathPrefix: "",
+			Child: &handler.HealthHandler{
+				DB:      _db,
+				Version: version,
+			},
+		},
+		{
+			PathPrefix: "",
+			Child:      &handler.MetricsHandler{},
+		},
+		{
+			PathPrefix: "",
+			Child: &handler.DocsHandler{
+				Spec: handler.APISpec(version, teamVersions, apiVersions),
+			},
+		},
 		{
 			PathPrefix: "/team",
 			Child: &handler.TeamHandler{
-				DB: _db,
+				DB:        cachedDB,
+				Standings: cachedStandings,
+			},
+			Middlewares: []mux.MiddlewareFunc{authenticator.Middleware, httpCache.Middleware},
+			Versions:    teamVersions,
+		},
+		{
+			PathPrefix: "/standings",
+			Child: &handler.StandingsHandler{
+				DB: cachedStandings,
 			},
+			Middlewares: []mux.MiddlewareFunc{authenticator.Middleware, httpCache.Middleware},
+			Versions:    apiVersions,
+		},
+		{
+			PathPrefix: "/events",
+			Child: &handler.EventsHandler{
+				DB:     _db,
+				Broker: broker,
+			},
+			// events are streamed, buffering them for an etag would hold back every event
+			Middlewares: []mux.MiddlewareFunc{authenticator.Middleware},
+			Versions:    apiVersions,
+		},
+		{
+			PathPrefix: "/graphql",
+			Child: &handler.GraphQLHandler{
+				Schema:        schema,
+				MaxComplexity: int64(getEnvIntOrDefault("GRAPHQL_MAX_COMPLEXITY", 5000)),
+			},
+			// only GET queries get caching headers, POSTs pass through
+			Middlewares: []mux.MiddlewareFunc{authenticator.Middleware, httpCache.Middleware},
+			Versions:    apiVersions,
 		},
 	}
+
+	if adminToken := getEnvOrDefault("ADMIN_TOKEN", ""); adminToken != "" {
+		adminMiddleware := handler.AdminTokenMiddleware(adminToken)
+		childRouters = append(childRouters,
+			ChildRouter{
+				PathPrefix: "/admin/keys",
+				Child: &handler.APIKeyHandler{
+					DB: _db,
+				},
+				Middlewares: []mux.MiddlewareFunc{adminMiddleware},
+				Versions:    apiVersions,
+			},
+			ChildRouter{
+				PathPrefix: "/admin/webhooks",
+				Child: &handler.WebhookHandler{
+					DB: _db,
+				},
+				Middlewares: []mux.MiddlewareFunc{adminMiddleware},
+				Versions:    apiVersions,
+			},
+			ChildRouter{
+				PathPrefix: "/admin",
+				Child: &handler.AdminHandler{
+					DB:     _db,
+					Caches: []handler.CacheClearer{cachedDB, cachedStandings},
+				},
+				Middlewares: []mux.MiddlewareFunc{adminMiddleware},
+				Versions:    apiVersions,
+			},
+		)
+	} else {
+		log.Warn("ADMIN_TOKEN not set, admin routes are disabled")
+	}
+
+	return childRouters
 }
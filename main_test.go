@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/events"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/mellena1/RSC-Spreadsheet-API/graph"
+	"github.com/mellena1/RSC-Spreadsheet-API/handler"
+	"github.com/mellena1/RSC-Spreadsheet-API/logging"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+// registeredOperations lists every method and path served by the router, as "METHOD path"
+func registeredOperations(t *testing.T, router *mux.Router) []string {
+	seen := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// subrouters for path prefixes don't have methods
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		for _, method := range methods {
+			seen[method+" "+path] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var ops []string
+	for op := range seen {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// Test_OpenAPISpecMatchesRoutes fails when a route is added or removed without updating handler.APISpec
+func Test_OpenAPISpecMatchesRoutes(t *testing.T) {
+	os.Setenv("ADMIN_TOKEN", "test")
+	defer os.Unsetenv("ADMIN_TOKEN")
+
+	router := makeHTTPRouter(&db.DB{}, events.NewBroker(), teamVersions())
+	registered := registeredOperations(t, router)
+	require.Contains(t, registered, "GET /team")
+	require.NotContains(t, registered, "GET /standings")
+
+	documented := handler.APISpec(version, teamVersions(), apiVersions).Operations()
+	sort.Strings(documented)
+
+	require.Equal(t, registered, documented)
+}
+
+func Test_wrapHTTPRouter_CORS(t *testing.T) {
+	os.Setenv("CORS_ALLOWED_ORIGINS", "https://standings.example.com, https://other.example.com")
+	defer os.Unsetenv("CORS_ALLOWED_ORIGINS")
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/team", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+	h := wrapHTTPRouter(router)
+
+	tests := []struct {
+		name                string
+		method              string
+		origin              string
+		expectedStatusCode  int
+		expectedAllowOrigin string
+	}{
+		{
+			name:                "Preflight from allowed origin",
+			method:              "OPTIONS",
+			origin:              "https://standings.example.com",
+			expectedStatusCode:  200,
+			expectedAllowOrigin: "https://standings.example.com",
+		},
+		{
+			name:                "Request from allowed origin",
+			method:              "GET",
+			origin:              "https://other.example.com",
+			expectedStatusCode:  200,
+			expectedAllowOrigin: "https://other.example.com",
+		},
+		{
+			name:                "Request from other origin",
+			method:              "GET",
+			origin:              "https://evil.example.com",
+			expectedStatusCode:  200,
+			expectedAllowOrigin: "",
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/v1/team", nil)
+		req.Header.Set("Origin", test.origin)
+		if test.method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			req.Header.Set("Access-Control-Request-Headers", "X-API-Key")
+		}
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		require.Equalf(t, test.expectedStatusCode, recorder.Code, "%q wrong status code", test.name)
+		require.Equalf(t, test.expectedAllowOrigin, recorder.Header().Get("Access-Control-Allow-Origin"), "%q wrong allowed origin", test.name)
+	}
+}
+
+func Test_wrapHTTPRouter_logsUnmatchedRequests(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/team", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+	h := wrapHTTPRouter(router)
+
+	tests := []struct {
+		method             string
+		path               string
+		expectedStatusCode int
+	}{
+		{method: "GET", path: "/v1/nope", expectedStatusCode: http.StatusNotFound},
+		{method: "DELETE", path: "/v1/team", expectedStatusCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		hook.Reset()
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+		require.Equal(t, test.expectedStatusCode, recorder.Code)
+		entry := hook.LastEntry()
+		require.NotNilf(t, entry, "%s %s should have been logged", test.method, test.path)
+		require.Equal(t, test.expectedStatusCode, entry.Data["status"])
+		require.Equal(t, test.path, entry.Data["path"])
+		require.NotEmpty(t, recorder.Header().Get(logging.RequestIDHeader))
+	}
+}
+
+func Test_legacyAPIVersion(t *testing.T) {
+	v := legacyAPIVersion()
+	require.False(t, v.Deprecated())
+	require.True(t, v.Sunset.IsZero())
+
+	os.Setenv("LEGACY_API_DEPRECATION_DATE", "2026-11-01")
+	defer os.Unsetenv("LEGACY_API_DEPRECATION_DATE")
+	os.Setenv("LEGACY_API_SUNSET_DATE", "2027-05-01")
+	defer os.Unsetenv("LEGACY_API_SUNSET_DATE")
+
+	v = legacyAPIVersion()
+	require.Equal(t, time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC), v.Deprecation)
+	require.Equal(t, time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC), v.Sunset)
+	require.Equal(t, "v1", v.Successor)
+}
+
+type countingStore struct {
+	teamCalls, standingCalls int
+}
+
+func (s *countingStore) GetAllTeams(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Team, error) {
+	s.teamCalls++
+	return []models.Team{{TeamID: "1", Name: "A"}}, nil
+}
+
+func (s *countingStore) GetAllStandings(ctx context.Context, query db.GetAllTeamsQuery) ([]models.Standing, error) {
+	s.standingCalls++
+	return nil, nil
+}
+
+func (s *countingStore) GetStandingsByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Standing, error) {
+	s.standingCalls++
+	return []models.Standing{{Team: models.Team{TeamID: "1"}, OverallRecord: models.Record{Wins: 3}}}, nil
+}
+
+func (s *countingStore) GetPlayersByTeamIDs(ctx context.Context, teamIDs []string) ([]models.Player, error) {
+	return nil, nil
+}
+
+func Test_graphStore_cached(t *testing.T) {
+	backing := &countingStore{}
+	store := graphStore{
+		Datastore:            db.NewCachedDatastore(backing, 10),
+		CachedStandingsStore: db.NewCachedStandingsStore(backing, 10),
+		players:              backing,
+	}
+	schema, err := graph.NewSchema(store, 6)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp := schema.Exec(context.Background(), `{ teams { name standing { overallRecord { wins } } players { name } } }`, "", nil)
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{"teams":[{"name":"A","standing":{"overallRecord":{"wins":3}},"players":[]}]}`, string(resp.Data))
+	}
+	require.Equal(t, 1, backing.teamCalls)
+	require.Equal(t, 1, backing.standingCalls)
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "rsc"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of postgres queries by Datastore method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	sheetFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sheet_fetch_duration_seconds",
+		Help:      "Latency of fetching a sheet from the Google Sheets API.",
+		Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"sheet"})
+
+	sheetFetchFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sheet_fetch_failures_total",
+		Help:      "Number of failed fetches from the Google Sheets API.",
+	}, []string{"sheet"})
+
+	syncRowsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_rows_ingested_total",
+		Help:      "Number of sheet rows written to the db by syncs.",
+	}, []string{"sheet"})
+
+	syncRowsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_rows_rejected_total",
+		Help:      "Number of sheet rows that failed to parse during syncs.",
+	}, []string{"sheet"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	cacheEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_entries",
+		Help:      "Number of entries in a cache.",
+	}, []string{"cache"})
+
+	lastSyncTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_sync_timestamp_seconds",
+		Help:      "Unix time of the last successful sheet sync.",
+	})
+)
+
+// ObserveDBQuery records how long a Datastore method took, meant to be deferred with the start time
+func ObserveDBQuery(method string, start time.Time) {
+	dbQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSheetFetch records how long a sheet fetch took and whether it failed
+func ObserveSheetFetch(sheet string, start time.Time, err error) {
+	sheetFetchDuration.WithLabelValues(sheet).Observe(time.Since(start).Seconds())
+	if err != nil {
+		sheetFetchFailures.WithLabelValues(sheet).Inc()
+	}
+}
+
+// AddRowsIngested counts rows from a sheet that were written to the db
+func AddRowsIngested(sheet string, n int) {
+	syncRowsIngested.WithLabelValues(sheet).Add(float64(n))
+}
+
+// AddRowsRejected counts rows from a sheet that couldn't be parsed
+func AddRowsRejected(sheet string, n int) {
+	syncRowsRejected.WithLabelValues(sheet).Add(float64(n))
+}
+
+// CacheHit counts a cache lookup that found an entry
+func CacheHit(cache string) {
+	cacheRequests.WithLabelValues(cache, "hit").Inc()
+}
+
+// CacheMiss counts a cache lookup that didn't find an entry
+func CacheMiss(cache string) {
+	cacheRequests.WithLabelValues(cache, "miss").Inc()
+}
+
+// SetCacheEntries records how many entries a cache holds
+func SetCacheEntries(cache string, n int) {
+	cacheEntries.WithLabelValues(cache).Set(float64(n))
+}
+
+// SetLastSync records the time of the last successful sync
+func SetLastSync(t time.Time) {
+	lastSyncTimestamp.Set(float64(t.Unix()))
+}
+
+// RegisterDataAge registers a gauge reporting how many seconds old the synced data is.
+// The gauge reports -1 until the first sync has happened.
+func RegisterDataAge(lastSync func() time.Time) error {
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "data_age_seconds",
+		Help:      "Seconds since the last successful sheet sync.",
+	}, func() float64 {
+		t := lastSync()
+		if t.IsZero() {
+			return -1
+		}
+		return time.Since(t).Seconds()
+	}))
+}
+
+// statusRecorder keeps track of the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// routeTemplate gets the template of the route on router matching r, so that ids in paths don't explode label cardinality
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return "unmatched"
+	}
+	tmpl, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	return tmpl
+}
+
+// HTTPMiddleware records request counts and latency by the route they match on router. It wraps the router
+// from outside, rather than being added with router.Use, so requests that 404 or 405 are recorded as unmatched.
+func HTTPMiddleware(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			route := routeTemplate(router, r)
+
+			next.ServeHTTP(recorder, r)
+
+			httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.code)).Inc()
+			httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		})
+	}
+}
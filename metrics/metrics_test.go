@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func Test_HTTPMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	sub := router.PathPrefix("/team").Subrouter()
+	sub.HandleFunc("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	h := HTTPMiddleware(router)(router)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/team/1", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/team/2", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/players", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/team/1", nil))
+
+	require.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues("/team/{id}", "GET", "404")))
+	require.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues("unmatched", "GET", "404")))
+	require.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues("unmatched", "POST", "405")))
+}
+
+func Test_ObserveSheetFetch(t *testing.T) {
+	ObserveSheetFetch("failing sheet", time.Now(), errTest)
+	ObserveSheetFetch("failing sheet", time.Now(), nil)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(sheetFetchFailures.WithLabelValues("failing sheet")))
+}
+
+func Test_SetLastSync(t *testing.T) {
+	SetLastSync(time.Unix(1600000000, 0))
+
+	require.Equal(t, float64(1600000000), testutil.ToFloat64(lastSyncTimestamp))
+}
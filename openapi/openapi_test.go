@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type inner struct {
+	Wins int `json:"wins"`
+}
+
+type Embedded struct {
+	Name string `json:"name"`
+}
+
+type outer struct {
+	Embedded
+	ID       string            `json:"id"`
+	Division *string           `json:"division,omitempty"`
+	Count    int64             `json:"count"`
+	Records  []inner           `json:"records"`
+	Last     *inner            `json:"last,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	At       time.Time         `json:"at"`
+	Ignored  string            `json:"-"`
+	hidden   string
+}
+
+func Test_SchemaFor(t *testing.T) {
+	d := NewDocument(Info{Title: "test", Version: "1"})
+	s := d.SchemaFor(outer{})
+	require.Equal(t, &Schema{Ref: "#/components/schemas/outer"}, s)
+
+	actual, err := json.Marshal(d.Components.Schemas)
+	require.NoError(t, err)
+	expected := `{
+		"inner":{"type":"object","properties":{"wins":{"type":"integer","format":"int32"}},"required":["wins"]},
+		"outer":{"type":"object","properties":{
+			"name":{"type":"string"},
+			"id":{"type":"string"},
+			"division":{"type":"string","nullable":true},
+			"count":{"type":"integer","format":"int64"},
+			"records":{"type":"array","items":{"$ref":"#/components/schemas/inner"}},
+			"last":{"$ref":"#/components/schemas/inner"},
+			"labels":{"type":"object","additionalProperties":{"type":"string"}},
+			"at":{"type":"string","format":"date-time"}
+		},"required":["name","id","count","records","labels","at"]}
+	}`
+	require.JSONEq(t, expected, string(actual))
+}
+
+func Test_Operations(t *testing.T) {
+	d := NewDocument(Info{Title: "test", Version: "1"})
+	d.Add("GET", "/team", Operation{Summary: "list"})
+	d.Add("GET", "/team/{id}", Operation{Summary: "get"})
+	d.Add("post", "/team", Operation{Summary: "create"})
+
+	ops := d.Operations()
+	sort.Strings(ops)
+	require.Equal(t, []string{"GET /team", "GET /team/{id}", "POST /team"}, ops)
+	require.Equal(t, "create", d.Paths["/team"]["post"].Summary)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/db"
+	"github.com/mellena1/RSC-Spreadsheet-API/data/sheets"
+	log "github.com/sirupsen/logrus"
+)
+
+const rscSpreadsheetID = "1l99BZtpFdVB8M6xB7VJii4aAj5O33u6HUvZLGfwHB0k"
+
+// sheetBatchMaxAge is how long a batch of sheet ranges is reused, enough for every source of one sync to share it
+const sheetBatchMaxAge = 10 * time.Second
+
+// sourceFactories make every data source synced into the db. To sync a new sheet tab add a factory for it here
+// that fetches the tab's rows and writes them through db.SyncTx, the db works out the order to sync them in
+// from their dependencies.
+var sourceFactories = []func(gateways *gatewayPool) (db.Source, error){
+	teamStandingsSource,
+}
+
+// makeSources registers a source from every factory. <SOURCE>_SYNC_INTERVAL_SECONDS sets how often a source
+// is synced in the background, by default they're only synced at startup and when asked to.
+func makeSources() *db.Registry {
+	gateways := newGatewayPool()
+	registry := db.NewRegistry()
+	for _, f := range sourceFactories {
+		source, err := f(gateways)
+		if err != nil {
+			log.Fatalf("Error making data source: %v\n", err)
+		}
+
+		interval := getEnvIntOrDefault(strings.ToUpper(source.Name)+"_SYNC_INTERVAL_SECONDS", int(source.Schedule/time.Second))
+		source.Schedule = time.Duration(interval) * time.Second
+
+		if err := registry.Register(source); err != nil {
+			log.Fatalf("Error registering data source: %v\n", err)
+		}
+	}
+	return registry
+}
+
+// gatewayPool shares a sheets gateway between the sources that use the same credentials,
+// so their ranges are fetched in one batch
+type gatewayPool struct {
+	gateways map[sheets.Credentials]*sheets.Gateway
+}
+
+func newGatewayPool() *gatewayPool {
+	return &gatewayPool{gateways: map[sheets.Credentials]*sheets.Gateway{}}
+}
+
+// get returns the gateway for the credentials of source, making it if no other source has
+func (p *gatewayPool) get(source string) (*sheets.Gateway, error) {
+	creds := sheetCredentials(source)
+	if gateway, ok := p.gateways[creds]; ok {
+		return gateway, nil
+	}
+
+	gateway, err := sheets.NewGateway(context.TODO(), rscSpreadsheetID, creds, sheetBatchMaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("error making sheets gateway for %s: %v", source, err)
+	}
+	p.gateways[creds] = gateway
+	return gateway, nil
+}
+
+// sheetCredentials reads how a data source authenticates with the Sheets API from <SOURCE>_AUTH,
+// api_key (the default) uses RSC_SHEETS_API_TOKEN and the others use the file at <SOURCE>_CREDENTIALS_FILE
+func sheetCredentials(source string) sheets.Credentials {
+	prefix := strings.ToUpper(source)
+	creds := sheets.Credentials{
+		Method: getEnvOrDefault(prefix+"_AUTH", sheets.AuthAPIKey),
+		File:   getEnvOrDefault(prefix+"_CREDENTIALS_FILE", ""),
+	}
+	if creds.Method == sheets.AuthAPIKey {
+		creds.APIKey = fatalIfMissingEnvVar("RSC_SHEETS_API_TOKEN")
+	} else if creds.File == "" {
+		log.Fatalf("Must set env var: %s_CREDENTIALS_FILE", prefix)
+	}
+	return creds
+}
+
+// teamStandingsSource reads the standings from the snapshot at SHEET_SNAPSHOT if it's set, otherwise from the Sheets API
+func teamStandingsSource(gateways *gatewayPool) (db.Source, error) {
+	if path := getEnvOrDefault("SHEET_SNAPSHOT", ""); path != "" {
+		log.Infof("Reading team standings from snapshot %s instead of the Sheets API", path)
+		snapshot, err := sheets.NewSnapshotSheet(path)
+		if err != nil {
+			return db.Source{}, fmt.Errorf("error making SnapshotSheet: %v", err)
+		}
+		return db.NewTeamStandingsSource(snapshot), nil
+	}
+
+	sheet, err := makeLiveTeamStandingsSheet(gateways)
+	if err != nil {
+		return db.Source{}, err
+	}
+	return db.NewTeamStandingsSource(sheet), nil
+}
+
+func makeLiveTeamStandingsSheet(gateways *gatewayPool) (*sheets.TeamStandingsSheet, error) {
+	gateway, err := gateways.get(db.TeamStandingsSource)
+	if err != nil {
+		return nil, err
+	}
+	return sheets.NewTeamStandingsSheet(gateway, "All Teams Data"), nil
+}
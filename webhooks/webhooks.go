@@ -0,0 +1,186 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader holds the hex HMAC-SHA256 of the body, keyed with the subscription's secret
+	SignatureHeader = "X-RSC-Signature"
+	// EventHeader holds the type of the event being delivered
+	EventHeader = "X-RSC-Event"
+	// DeliveryHeader holds a unique id for the delivery, the same across retries
+	DeliveryHeader = "X-RSC-Delivery"
+
+	queueSize = 1000
+)
+
+// Store holds the subscriptions to deliver to and a log of deliveries
+type Store interface {
+	GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+type job struct {
+	sub        models.WebhookSubscription
+	eventType  models.EventType
+	deliveryID string
+	body       []byte
+}
+
+// Dispatcher delivers events to every subscribed webhook in the background,
+// retrying failed deliveries with exponential backoff
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	baseBackoff time.Duration
+
+	queue chan job
+}
+
+// NewDispatcher makes a Dispatcher that tries each delivery up to maxAttempts times,
+// waiting baseBackoff before the first retry and doubling the wait after each one
+func NewDispatcher(store Store, client *http.Client, maxAttempts int, baseBackoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		client:      client,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		queue:       make(chan job, queueSize),
+	}
+}
+
+// Sign returns the signature of body for the given secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Start starts workers delivering queued events until ctx is done
+func (d *Dispatcher) Start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-d.queue:
+					d.deliver(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+// Dispatch queues the events for delivery to every subscription that wants them
+func (d *Dispatcher) Dispatch(events []models.Event) {
+	subs, err := d.store.GetWebhookSubscriptions(context.Background())
+	if err != nil {
+		log.Errorf("Unable to get webhook subscriptions, dropping %d events: %v", len(events), err)
+		return
+	}
+
+	for _, event := range events {
+		body, err := json.Marshal(&event)
+		if err != nil {
+			log.Errorf("Unable to marshal event: %v", err)
+			continue
+		}
+
+		for _, sub := range subs {
+			if !sub.Wants(event.Type) {
+				continue
+			}
+			select {
+			case d.queue <- job{sub: sub, eventType: event.Type, deliveryID: newDeliveryID(), body: body}:
+			default:
+				log.Errorf("Webhook queue is full, dropping %s event for subscription %d", event.Type, sub.ID)
+			}
+		}
+	}
+}
+
+// backoff returns how long to wait before the given retry, with up to 50% jitter
+func (d *Dispatcher) backoff(retry int) time.Duration {
+	wait := d.baseBackoff * time.Duration(1<<uint(retry-1))
+	return wait + time.Duration(mathrand.Int63n(int64(wait)/2+1))
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d.backoff(attempt - 1)):
+			}
+		}
+
+		delivery := d.attempt(ctx, j)
+		delivery.Attempt = attempt
+		if err := d.store.RecordWebhookDelivery(ctx, delivery); err != nil {
+			log.Errorf("Unable to record webhook delivery: %v", err)
+		}
+		if delivery.Success {
+			return
+		}
+	}
+	log.Warnf("Gave up delivering %s event to webhook %d after %d attempts", j.eventType, j.sub.ID, d.maxAttempts)
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, j job) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		SubscriptionID: j.sub.ID,
+		EventType:      j.eventType,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", j.sub.URL, bytes.NewReader(j.body))
+	if err != nil {
+		errStr := err.Error()
+		delivery.Error = &errStr
+		delivery.DeliveredAt = time.Now()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(j.eventType))
+	req.Header.Set(DeliveryHeader, j.deliveryID)
+	req.Header.Set(SignatureHeader, Sign(j.sub.Secret, j.body))
+
+	resp, err := d.client.Do(req)
+	delivery.DeliveredAt = time.Now()
+	if err != nil {
+		errStr := err.Error()
+		delivery.Error = &errStr
+		return delivery
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = &resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		errStr := fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+		delivery.Error = &errStr
+	}
+	return delivery
+}
@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mellena1/RSC-Spreadsheet-API/data/models"
+	"github.com/stretchr/testify/require"
+)
+
+type storeMock struct {
+	subs    []models.WebhookSubscription
+	subsErr error
+
+	mu         sync.Mutex
+	deliveries []models.WebhookDelivery
+}
+
+func (s *storeMock) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.subs, s.subsErr
+}
+
+func (s *storeMock) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *storeMock) getDeliveries() []models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.WebhookDelivery{}, s.deliveries...)
+}
+
+func Test_Sign(t *testing.T) {
+	require.Equal(t, "sha256=9307b3b915efb5171ff14d8cb55fbcc798c6c0ef1456d66ded1a6aa723a58b7b", Sign("key", []byte("hello")))
+}
+
+func Test_backoff(t *testing.T) {
+	d := NewDispatcher(&storeMock{}, http.DefaultClient, 3, time.Second)
+
+	for retry, base := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		wait := d.backoff(retry + 1)
+		require.GreaterOrEqual(t, int64(wait), int64(base))
+		require.LessOrEqual(t, int64(wait), int64(base+base/2))
+	}
+}
+
+func Test_Dispatcher(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies []string
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	store := &storeMock{subs: []models.WebhookSubscription{
+		{ID: 1, URL: server.URL, Secret: "secret", EventTypes: []models.EventType{models.EventTeamAdded}},
+		{ID: 2, URL: server.URL, Secret: "secret", EventTypes: []models.EventType{models.EventTeamRemoved}},
+	}}
+	d := NewDispatcher(store, server.Client(), 3, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	d.Start(ctx, 1)
+
+	createdAt := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	d.Dispatch([]models.Event{{Type: models.EventTeamAdded, Current: &models.Standing{}, CreatedAt: createdAt}})
+
+	require.Eventually(t, func() bool { return len(store.getDeliveries()) == 2 }, time.Second, time.Millisecond)
+
+	deliveries := store.getDeliveries()
+	require.Equal(t, false, deliveries[0].Success)
+	require.Equal(t, 500, *deliveries[0].StatusCode)
+	require.Equal(t, 1, deliveries[0].Attempt)
+	require.Equal(t, true, deliveries[1].Success)
+	require.Equal(t, 2, deliveries[1].Attempt)
+	require.Equal(t, 1, deliveries[1].SubscriptionID)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 2)
+	require.Equal(t, "team.added", requests[1].Header.Get(EventHeader))
+	require.Equal(t, requests[0].Header.Get(DeliveryHeader), requests[1].Header.Get(DeliveryHeader))
+	require.Equal(t, Sign("secret", []byte(bodies[1])), requests[1].Header.Get(SignatureHeader))
+	require.JSONEq(t, `{"id":0,"type":"team.added","current":{"team":{"id":"","name":"","franchise":"","tier":"","conference":""},"overall_record":{"wins":0,"losses":0},"conference_record":{"wins":0,"losses":0},"series_record":{"wins":0,"losses":0},"win_percentage":0,"game_differential":0,"games_behind":0},"created_at":"2020-09-01T12:00:00Z"}`, bodies[1])
+}
+
+func Test_Dispatcher_GivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	store := &storeMock{subs: []models.WebhookSubscription{{ID: 1, URL: server.URL}}}
+	d := NewDispatcher(store, server.Client(), 2, time.Millisecond)
+
+	d.deliver(context.Background(), job{sub: store.subs[0], eventType: models.EventTeamRemoved})
+
+	deliveries := store.getDeliveries()
+	require.Len(t, deliveries, 2)
+	require.Equal(t, "unexpected status code 502", *deliveries[1].Error)
+}
+
+func Test_Dispatcher_SubscriptionsError(t *testing.T) {
+	store := &storeMock{subsErr: errors.New("db down")}
+	d := NewDispatcher(store, http.DefaultClient, 2, time.Millisecond)
+
+	d.Dispatch([]models.Event{{Type: models.EventTeamAdded}})
+
+	require.Len(t, d.queue, 0)
+}